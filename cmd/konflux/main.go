@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"text/tabwriter"
 
 	k "github.com/openshift-pipelines-konflux/hack/internal/konflux"
 	"gopkg.in/yaml.v2"
@@ -15,48 +18,153 @@ import (
 const (
 	GithubOrg          = "openshift-pipelines-konflux"
 	DefaultImageSuffix = "-rhel9"
-	DefaultImagePrefix = "pipeline-"
+
+	DefaultTenantNamespace = "tekton-ecosystem-tenant"
+	DefaultSBOMWebhook     = "https://bombino.api.redhat.com/v1/sbom/quay/push"
+	DefaultReleaseSecret   = "release-registry-openshift-pipelines-quay"
+	DefaultBotName         = "openshift-pipelines-bot"
+	DefaultBotEmail        = "pipelines-extcomm@redhat.com"
+
+	DockerBuildPipelineURL = "https://raw.githubusercontent.com/openshift-pipelines/operator/refs/heads/main/.tekton/docker-build-ta.yaml"
+	FBCBuildPipelineURL    = "https://raw.githubusercontent.com/openshift-pipelines/operator/refs/heads/main/.tekton/fbc-build.yaml"
+
+	EnterpriseContractPipelineURL  = "https://github.com/konflux-ci/build-definitions"
+	EnterpriseContractPipelinePath = "pipelines/enterprise-contract.yaml"
+	BundleE2EPipelineURL           = "https://github.com/openshift-pipelines/operator"
+	BundleE2EPipelinePath          = ".konflux/tekton/bundle-e2e-pipeline.yaml"
+
+	DefaultUpdateSchedule    = "0 1 * * *"    // At 1AM everyday
+	DefaultAutoMergeSchedule = "*/30 * * * *" // At every 30 minutes
+	DefaultMergeMethod       = "rebase"
+	DefaultRobotSecret       = "OPENSHIFT_PIPELINES_ROBOT"
+	DefaultTokenSecret       = "GITHUB_TOKEN"
 )
 
 func main() {
 	flag.Parse()
-	configFiles := flag.Args()
+	if flag.Arg(0) == "template-funcs" {
+		if err := printTemplateFuncs(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if flag.Arg(0) == "release" {
+		if err := release(context.Background(), flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if flag.Arg(0) == "images" {
+		if err := printImages(os.Stdout, flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	configFile := "config/konflux.yaml"
-	if len(configFiles) == 1 {
-		configFile = configFiles[0]
+	if flag.NArg() == 1 {
+		configFile = flag.Arg(0)
+	}
+	applications, err := loadApplications(configFile, nil)
+	if err != nil {
+		log.Fatal(err)
 	}
+
+	// The names of the resources of every application are checked before generating anything
+	if err := k.CheckResourceNames(applications); err != nil {
+		log.Fatal(err)
+	}
+	for _, application := range applications {
+		log.Printf("Loaded application: %s", application.Name)
+		if err := k.GenerateConfig(application); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	log.Printf("Done:")
+}
+
+// loadApplications reads the applications of the konflux config for the versions, or for all the
+// configured versions when none is given.
+func loadApplications(configFile string, versions []string) ([]k.Application, error) {
 	configDir := filepath.Dir(configFile)
 
 	// Read the main konflux config using the generic readResource function
 	config, err := readConfig(configDir, filepath.Base(configFile))
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
+	}
+	updateTenant(&config.Tenant)
+	if len(versions) == 0 {
+		versions = config.Versions
 	}
 
-	for _, version := range config.Versions {
+	applications := []k.Application{}
+	for _, version := range versions {
 		versionConfig, err := readResource[k.ReleaseConfig](configDir, "releases", version)
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
+		}
+		if versionConfig.Version.Version, err = k.ParseReleaseVersion(version); err != nil {
+			return nil, err
 		}
-		versionConfig.Version.Version = version
 		log.Printf("%v", versionConfig)
 		for _, applicationName := range config.Applications {
 			// Read application using the generic readResource function
-			applications, err := readApplications(configDir, applicationName, versionConfig)
+			versionApplications, err := readApplications(configDir, applicationName, versionConfig, config)
 			if err != nil {
-				log.Fatal(err)
-			}
-			for _, application := range applications {
-				log.Printf("Loaded application: %s", application.Name)
-				if err := k.GenerateConfig(application); err != nil {
-					log.Fatal(err)
-				}
+				return nil, err
 			}
+			applications = append(applications, versionApplications...)
+		}
+	}
+	if err := k.AssignBoussoleOwners(applications); err != nil {
+		return nil, err
+	}
+	return applications, nil
+}
 
+// printImages prints every image published by the release of a version: the image built by Konflux,
+// the image it is released to and its tags.
+func printImages(out io.Writer, args []string) error {
+	flags := flag.NewFlagSet("images", flag.ExitOnError)
+	version := flags.String("version", "", "Released version, e.g. 1.22")
+	releaseRegistry := flags.String("target-registry", k.DefaultReleaseRegistry, "Registry the images are released to")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *version == "" {
+		return fmt.Errorf("--version is required")
+	}
+	configFile := "config/konflux.yaml"
+	if flags.NArg() == 1 {
+		configFile = flags.Arg(0)
+	}
+	applications, err := loadApplications(configFile, []string{*version})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "APPLICATION\tCOMPONENT\tBUILD IMAGE\tRELEASE IMAGE\tTAGS")
+	for _, application := range applications {
+		for _, c := range application.Components {
+			images := c.Images(*releaseRegistry)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", application.Name, c.Name, images.Build, images.Release, strings.Join(images.Tags, ","))
 		}
 	}
+	return w.Flush()
+}
 
-	log.Printf("Done:")
+// printTemplateFuncs prints the functions available in the templates, with the result of their example.
+func printTemplateFuncs(out io.Writer) error {
+	for _, f := range k.TemplateFuncs() {
+		result, err := k.Eval(f.Example, nil)
+		if err != nil {
+			return fmt.Errorf("example of %s: %w", f.Name, err)
+		}
+		fmt.Fprintf(out, "%s: %s\n    %s\n    => %s\n", f.Name, f.Description, f.Example, strings.ReplaceAll(result, "\n", "\n       "))
+	}
+	return nil
 }
 
 // readResource reads any type of resource from YAML files
@@ -80,7 +188,7 @@ func readResource[T any](dir, resourceType, resourceName string) (T, error) {
 }
 
 // Helper functions using the generic readResource function
-func readApplications(dir, applicationName string, versionConfig k.ReleaseConfig) ([]k.Application, error) {
+func readApplications(dir, applicationName string, versionConfig k.ReleaseConfig, config k.Config) ([]k.Application, error) {
 
 	log.Printf("Reading application: %s", applicationName)
 	applicationConfigs, err := readResource[[]k.ApplicationConfig](dir, "applications", applicationName)
@@ -90,14 +198,30 @@ func readApplications(dir, applicationName string, versionConfig k.ReleaseConfig
 	}
 	var applications []k.Application
 
+	// Templates next to the konflux config override the embedded ones
+	templateDir := filepath.Join(dir, "templates")
+	if _, err := os.Stat(templateDir); err != nil {
+		templateDir = ""
+	}
+
 	for _, applicationConfig := range applicationConfigs {
 		application := k.Application{
-			Name:            applicationConfig.Name,
-			Components:      []k.Component{},
-			Release:         &versionConfig.Version,
-			Org:             applicationConfig.Org,
-			ReleaseToGitHub: applicationConfig.ReleaseToGitHub,
-			AutoRelease:     true,
+			Name:         applicationConfig.Name,
+			Components:   []k.Component{},
+			Release:      &versionConfig.Version,
+			Org:          applicationConfig.Org,
+			Releases:     applicationConfig.Releases,
+			TemplateDir:  templateDir,
+			Tenant:       config.Tenant,
+			ShortenNames: config.ShortenNames,
+			Tests:        applicationConfig.Tests,
+			Boussole:     applicationConfig.Boussole,
+		}
+		if err := updateTests(&application); err != nil {
+			return []k.Application{}, err
+		}
+		if err := updateReleases(&application, applicationConfig.ReleaseToGitHub); err != nil {
+			return []k.Application{}, err
 		}
 		for _, repoName := range applicationConfig.Repositories {
 			repo, err := readRepository(dir, repoName, &application, versionConfig.Branches[repoName])
@@ -116,32 +240,39 @@ func readApplications(dir, applicationName string, versionConfig k.ReleaseConfig
 	return applications, nil
 }
 
-func updateRepository(repo *k.Repository, a k.Application) error {
+func updateRepository(repo *k.Repository, a *k.Application) error {
 	repo.Application = a
-	if a.Org == "" {
-		a.Org = GithubOrg
+	org := a.Org
+	if org == "" {
+		org = GithubOrg
 	}
 	if repo.Url == "" {
-		repository := fmt.Sprintf("https://github.com/%s/%s.git", a.Org, repo.Name)
+		repository := fmt.Sprintf("https://github.com/%s/%s.git", org, repo.Name)
 		repo.Url = repository
 	}
 
-	var branchName, upstreamBranch string
-
-	if a.Release.Version == "main" || a.Release.Version == "next" {
-		branchName = "main"
-		upstreamBranch = "main"
-	} else {
-		branchName = "release-v" + a.Release.Version + ".x"
-		upstreamBranch = "main"
-	}
-
 	branch := &repo.Branch
 	if branch.Name == "" {
-		branch.Name = branchName
+		branch.Name = a.Release.Version.Branch()
 	}
 	if branch.UpstreamBranch == "" {
-		branch.UpstreamBranch = upstreamBranch
+		branch.UpstreamBranch = "main"
+	}
+
+	if repo.Boussole == nil {
+		repo.Boussole = a.Boussole
+	}
+
+	// GitHub workflows, defaulted from the release
+	repo.GitHub = repo.GitHub.WithDefaults(a.Release.GitHub).WithDefaults(k.GitHub{
+		UpdateSchedule:    DefaultUpdateSchedule,
+		AutoMergeSchedule: DefaultAutoMergeSchedule,
+		MergeMethod:       DefaultMergeMethod,
+		RobotSecret:       DefaultRobotSecret,
+		TokenSecret:       DefaultTokenSecret,
+	})
+	if err := repo.GitHub.Validate(); err != nil {
+		return fmt.Errorf("repository %s: %w", repo.Name, err)
 	}
 
 	// Tekton
@@ -164,11 +295,15 @@ func readRepository(dir, repoName string, app *k.Application, branch k.Branch) (
 	}
 
 	repository.Branch = branch
-	if err := updateRepository(&repository, *app); err != nil {
+	// Template overrides are relative to the config directory
+	for name, file := range repository.Templates {
+		repository.Templates[name] = filepath.Join(dir, file)
+	}
+	if err := updateRepository(&repository, app); err != nil {
 		return k.Repository{}, err
 	}
 	for i := range repository.Components {
-		if err := UpdateComponent(&repository.Components[i], repository, *app); err != nil {
+		if err := UpdateComponent(&repository.Components[i], &repository, app); err != nil {
 			return k.Repository{}, err
 		}
 	}
@@ -176,7 +311,7 @@ func readRepository(dir, repoName string, app *k.Application, branch k.Branch) (
 }
 
 // UpdateComponent function can be modified  if we want to override the fields at component level.
-func UpdateComponent(c *k.Component, repo k.Repository, app k.Application) error {
+func UpdateComponent(c *k.Component, repo *k.Repository, app *k.Application) error {
 	log.Printf("Updating component: %s", c.Name)
 	version := *app.Release
 
@@ -197,6 +332,9 @@ func UpdateComponent(c *k.Component, repo k.Repository, app k.Application) error
 	if c.PrefetchInput == "" {
 		c.PrefetchInput = "{\"type\": \"rpm\", \"path\": \".konflux/rpms\"}"
 	}
+	if err := updateBuild(&c.Build, c.Name, *repo); err != nil {
+		return err
+	}
 	if version.ImageSuffix != "None" {
 		c.ImageSuffix = version.ImageSuffix
 		if c.ImageSuffix == "" {
@@ -212,6 +350,143 @@ func UpdateComponent(c *k.Component, repo k.Repository, app k.Application) error
 	return nil
 }
 
+// updateBuild infers the build configuration from the component name when it's not set:
+// "index" components are file-based catalogs and "bundle" components are single platform bundles.
+func updateBuild(b *k.Build, name string, repo k.Repository) error {
+	if b.Kind == "" {
+		switch {
+		case strings.Contains(name, "index"):
+			b.Kind = k.BuildKindFBC
+		case strings.Contains(name, "bundle"):
+			b.Kind = k.BuildKindBundle
+		default:
+			b.Kind = k.BuildKindContainer
+		}
+	}
+	switch b.Kind {
+	case k.BuildKindContainer:
+	case k.BuildKindFBC:
+	case k.BuildKindBundle:
+		if len(b.Platforms) == 0 {
+			b.Platforms = []string{"linux/x86_64"}
+		}
+		if b.BuildImageIndex == nil {
+			buildImageIndex := false
+			b.BuildImageIndex = &buildImageIndex
+		}
+	default:
+		return fmt.Errorf("unknown build kind %q for component %s", b.Kind, name)
+	}
+	if b.PipelineURL == "" {
+		b.PipelineURL = DockerBuildPipelineURL
+		if b.Kind == k.BuildKindFBC && repo.Branch.Name != "main" {
+			b.PipelineURL = FBCBuildPipelineURL
+		}
+	}
+	return nil
+}
+
+// updateTests defaults the test scenarios of the application to the enterprise contract, and the
+// pipelines, params and contexts of the enterprise-contract and bundle-e2e scenarios.
+func updateTests(a *k.Application) error {
+	if len(a.Tests) == 0 {
+		a.Tests = []k.TestScenario{{Name: k.TestScenarioEnterpriseContract}}
+	}
+	names := map[string]bool{}
+	for i := range a.Tests {
+		t := &a.Tests[i]
+		if t.Type == "" {
+			t.Type = k.TestScenarioEnterpriseContract
+		}
+		if t.Name == "" {
+			t.Name = t.Type
+		}
+		if names[t.Name] {
+			return fmt.Errorf("duplicate test scenario %q in application %s", t.Name, a.Name)
+		}
+		names[t.Name] = true
+		switch t.Type {
+		case k.TestScenarioEnterpriseContract:
+			if t.Pipeline.URL == "" {
+				t.Pipeline = k.GitResolver{URL: EnterpriseContractPipelineURL, PathInRepo: EnterpriseContractPipelinePath}
+			}
+			if t.Params == nil {
+				t.Params = []k.Param{{Name: "TIMEOUT", Value: "15m0s"}, {Name: "SINGLE_COMPONENT", Value: "true"}}
+			}
+			if t.Contexts == nil {
+				t.Contexts = []k.TestContext{{Name: "component", Description: "execute the integration test for a Snapshot of the `component` type"}}
+			}
+		case k.TestScenarioBundleE2E:
+			if t.Pipeline.URL == "" {
+				t.Pipeline = k.GitResolver{URL: BundleE2EPipelineURL, PathInRepo: BundleE2EPipelinePath}
+			}
+			if t.Contexts == nil {
+				t.Contexts = []k.TestContext{{Name: "push", Description: "execute the integration test for a Snapshot created for a `push` event"}}
+			}
+		case k.TestScenarioPipeline:
+			if t.Pipeline.URL == "" || t.Pipeline.PathInRepo == "" {
+				return fmt.Errorf("test scenario %q of application %s requires pipeline url and path-in-repo", t.Name, a.Name)
+			}
+		default:
+			return fmt.Errorf("unknown type %q for test scenario %q of application %s", t.Type, t.Name, a.Name)
+		}
+		if t.Pipeline.Revision == "" {
+			t.Pipeline.Revision = "main"
+		}
+	}
+	return nil
+}
+
+// updateReleases defaults the release targets of the application to a registry release, and a GitHub
+// release not released automatically with release-to-github.
+func updateReleases(a *k.Application, releaseToGitHub bool) error {
+	if len(a.Releases) == 0 {
+		a.Releases = []k.ReleaseTarget{{}}
+		if releaseToGitHub {
+			autoRelease := false
+			a.Releases = append(a.Releases, k.ReleaseTarget{Name: "github", GitHub: true, AutoRelease: &autoRelease})
+		}
+	} else if releaseToGitHub {
+		return fmt.Errorf("application %s sets both releases and release-to-github, add a github release instead", a.Name)
+	}
+	names := map[string]bool{}
+	for _, target := range a.Releases {
+		if names[target.Name] {
+			return fmt.Errorf("duplicate release %q in application %s", target.Name, a.Name)
+		}
+		names[target.Name] = true
+		if err := k.ValidateReleaseImage(a.ReleaseImage(target)); err != nil {
+			return fmt.Errorf("release %q of application %s: %w", target.Name, a.Name, err)
+		}
+	}
+	return nil
+}
+
+// updateTenant defaults the tenant to tekton-ecosystem-tenant.
+func updateTenant(t *k.Tenant) {
+	if t.Namespace == "" {
+		t.Namespace = DefaultTenantNamespace
+	}
+	if t.Registry == "" {
+		t.Registry = "quay.io/redhat-user-workloads/" + t.Namespace
+	}
+	if t.Policy == "" {
+		t.Policy = t.Namespace + "/" + t.Namespace
+	}
+	if t.SBOMWebhook == "" {
+		t.SBOMWebhook = DefaultSBOMWebhook
+	}
+	if t.ReleaseSecret == "" {
+		t.ReleaseSecret = DefaultReleaseSecret
+	}
+	if t.BotName == "" {
+		t.BotName = DefaultBotName
+	}
+	if t.BotEmail == "" {
+		t.BotEmail = DefaultBotEmail
+	}
+}
+
 // readConfig reads the main konflux config file
 func readConfig(dir, configFile string) (k.Config, error) {
 	return readResource[k.Config](dir, "", configFile)
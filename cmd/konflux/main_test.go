@@ -0,0 +1,72 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	k "github.com/openshift-pipelines-konflux/hack/internal/konflux"
+)
+
+func TestUpdateTests(t *testing.T) {
+	enterpriseContract := k.GitResolver{URL: EnterpriseContractPipelineURL, Revision: "main", PathInRepo: EnterpriseContractPipelinePath}
+	enterpriseContractParams := []k.Param{{Name: "TIMEOUT", Value: "15m0s"}, {Name: "SINGLE_COMPONENT", Value: "true"}}
+	enterpriseContractContexts := []k.TestContext{{Name: "component", Description: "execute the integration test for a Snapshot of the `component` type"}}
+
+	tests := []struct {
+		name  string
+		tests []k.TestScenario
+		want  []k.TestScenario
+		err   string
+	}{{
+		name: "default enterprise contract",
+		want: []k.TestScenario{{Name: k.TestScenarioEnterpriseContract, Type: k.TestScenarioEnterpriseContract, Pipeline: enterpriseContract, Params: enterpriseContractParams, Contexts: enterpriseContractContexts}},
+	}, {
+		name:  "custom-named enterprise contract",
+		tests: []k.TestScenario{{Name: "ec-strict", Policy: "tekton-ecosystem-tenant/strict"}},
+		want:  []k.TestScenario{{Name: "ec-strict", Type: k.TestScenarioEnterpriseContract, Policy: "tekton-ecosystem-tenant/strict", Pipeline: enterpriseContract, Params: enterpriseContractParams, Contexts: enterpriseContractContexts}},
+	}, {
+		name:  "bundle end-to-end tests named after their type",
+		tests: []k.TestScenario{{Type: k.TestScenarioBundleE2E}},
+		want: []k.TestScenario{{
+			Name:     k.TestScenarioBundleE2E,
+			Type:     k.TestScenarioBundleE2E,
+			Pipeline: k.GitResolver{URL: BundleE2EPipelineURL, Revision: "main", PathInRepo: BundleE2EPipelinePath},
+			Contexts: []k.TestContext{{Name: "push", Description: "execute the integration test for a Snapshot created for a `push` event"}},
+		}},
+	}, {
+		name:  "pipeline with its revision",
+		tests: []k.TestScenario{{Name: "e2e", Type: k.TestScenarioPipeline, Pipeline: k.GitResolver{URL: "https://github.com/org/tests", Revision: "v1", PathInRepo: "e2e.yaml"}}},
+		want:  []k.TestScenario{{Name: "e2e", Type: k.TestScenarioPipeline, Pipeline: k.GitResolver{URL: "https://github.com/org/tests", Revision: "v1", PathInRepo: "e2e.yaml"}}},
+	}, {
+		name:  "pipeline without path",
+		tests: []k.TestScenario{{Name: "e2e", Type: k.TestScenarioPipeline, Pipeline: k.GitResolver{URL: "https://github.com/org/tests"}}},
+		err:   `test scenario "e2e" of application pipelines requires pipeline url and path-in-repo`,
+	}, {
+		name:  "duplicate name",
+		tests: []k.TestScenario{{}, {Name: k.TestScenarioEnterpriseContract, Type: k.TestScenarioBundleE2E}},
+		err:   `duplicate test scenario "enterprise-contract" in application pipelines`,
+	}, {
+		name:  "unknown type",
+		tests: []k.TestScenario{{Name: "e2e", Type: "e2e"}},
+		err:   `unknown type "e2e" for test scenario "e2e" of application pipelines`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &k.Application{Name: "pipelines", Tests: tt.tests}
+			err := updateTests(a)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(a.Tests, tt.want) {
+				t.Errorf("tests = %+v, want %+v", a.Tests, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	k "github.com/openshift-pipelines-konflux/hack/internal/konflux"
+	rel "github.com/openshift-pipelines-konflux/hack/internal/release"
+)
+
+const releaseUsage = `usage: konflux release <command> [flags] [config]
+
+commands:
+  notes  generate the release notes of a version from the upstream changes
+  bump   bump the patch version of a released version and regenerate its ReleasePlans
+  branch print the downstream branch of a version
+  pipelines  print the release pipeline revision of the ReleasePlans of every version`
+
+// release runs the release commands.
+func release(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New(releaseUsage)
+	}
+	switch args[0] {
+	case "notes":
+		return releaseNotes(ctx, args[1:])
+	case "bump":
+		return releaseBump(ctx, args[1:])
+	case "branch":
+		return releaseBranch(args[1:])
+	case "pipelines":
+		return releasePipelines(args[1:])
+	default:
+		return fmt.Errorf("unknown release command %q\n%s", args[0], releaseUsage)
+	}
+}
+
+// releaseNotes writes the Markdown release notes of a version, grouped by application and repository.
+func releaseNotes(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("notes", flag.ExitOnError)
+	version := flags.String("version", "", "Released version, e.g. 1.22")
+	previous := flags.String("previous", "", "Previously released patch version (defaults to the patch version before the configured one)")
+	workDir := flags.String("work-dir", "/tmp/konflux-release-notes", "Directory the repositories are cloned in")
+	output := flags.String("output", "", "File to write the release notes to (defaults to stdout)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *version == "" {
+		return fmt.Errorf("--version is required")
+	}
+	configFile := "config/konflux.yaml"
+	if flags.NArg() == 1 {
+		configFile = flags.Arg(0)
+	}
+	applications, err := loadApplications(configFile, []string{*version})
+	if err != nil {
+		return err
+	}
+	if len(applications) == 0 {
+		return fmt.Errorf("no application configured in %s", configFile)
+	}
+	if *previous == "" {
+		patch, err := applications[0].Release.Patch()
+		if err != nil {
+			return err
+		}
+		if p, ok, err := patch.PreviousPatch(); err != nil {
+			return err
+		} else if ok {
+			*previous = p.String()
+		}
+	}
+	notes, err := k.ReleaseNotes(ctx, applications, *previous, *workDir)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err := fmt.Fprint(os.Stdout, notes)
+		return err
+	}
+	return os.WriteFile(*output, []byte(notes), 0o644)
+}
+
+// releaseBump bumps the patch version of a version (konflux release bump 1.22 --patch), once the current
+// patch version was released, and regenerates the ReleasePlans of its applications.
+func releaseBump(ctx context.Context, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("usage: konflux release bump <version> --patch [config]")
+	}
+	version := args[0]
+	flags := flag.NewFlagSet("bump", flag.ExitOnError)
+	patch := flags.Bool("patch", false, "Bump the patch version")
+	releaseRegistry := flags.String("target-registry", k.DefaultReleaseRegistry, "Registry the images are released to")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if !*patch {
+		return fmt.Errorf("only patch versions can be bumped, --patch is required")
+	}
+	configFile := "config/konflux.yaml"
+	if flags.NArg() == 1 {
+		configFile = flags.Arg(0)
+	}
+	applications, err := loadApplications(configFile, []string{version})
+	if err != nil {
+		return err
+	}
+	next, err := k.BumpPatchVersion(ctx, rel.RegistryChecker{Registry: *releaseRegistry}, applications)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Bumping %s to %s", version, next)
+	if err := updatePatchVersion(filepath.Join(filepath.Dir(configFile), "releases", version+".yaml"), next.String()); err != nil {
+		return err
+	}
+	for _, application := range applications {
+		application.Release.PatchVersion = next.String()
+		if err := k.GenerateReleasePlans(application); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var patchVersionPattern = regexp.MustCompile(`(?m)^patch-version:.*$`)
+
+// updatePatchVersion sets the patch-version of a release file, keeping the rest of the file as is.
+func updatePatchVersion(file, patchVersion string) error {
+	in, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if !patchVersionPattern.Match(in) {
+		return fmt.Errorf("no patch-version in %s", file)
+	}
+	out := patchVersionPattern.ReplaceAll(in, []byte("patch-version: "+patchVersion))
+	return os.WriteFile(file, out, 0o644)
+}
+
+// releaseBranch prints the downstream branch of a version (konflux release branch 1.22).
+func releaseBranch(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: konflux release branch <version>")
+	}
+	version, err := k.ParseReleaseVersion(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println(version.Branch())
+	return nil
+}
+
+// releasePipelines prints the revision of the release pipeline and the release image of the ReleasePlans of every version.
+func releasePipelines(args []string) error {
+	flags := flag.NewFlagSet("pipelines", flag.ExitOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	configFile := "config/konflux.yaml"
+	if flags.NArg() == 1 {
+		configFile = flags.Arg(0)
+	}
+	applications, err := loadApplications(configFile, nil)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tAPPLICATION\tRELEASE PLAN\tPIPELINE REVISION\tRELEASE IMAGE")
+	for _, application := range applications {
+		for _, target := range application.Releases {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", application.Release.Version, application.Name, application.ReleasePlanName(target), application.PipelineRevision(target), application.ReleaseImage(target))
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,193 @@
+// release publishes a component released by Konflux: it copies the image of the Snapshot to the
+// target registry and creates the GitHub release of the source repository.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/openshift-pipelines-konflux/hack/internal/forge"
+	"github.com/openshift-pipelines-konflux/hack/internal/konflux"
+	"github.com/openshift-pipelines-konflux/hack/internal/release"
+)
+
+const usage = `usage: release <command> [flags]
+
+commands:
+  images  copy the image of the released component of a Snapshot to the target registry
+  github  create or update the GitHub release of a commit and upload its assets`
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+	ctx := context.Background()
+	var err error
+	switch os.Args[1] {
+	case "images":
+		err = publishImages(ctx, os.Args[2:])
+	case "github":
+		err = publishGitHubRelease(ctx, os.Args[2:])
+	default:
+		log.Fatalf("unknown command %q\n%s", os.Args[1], usage)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func publishImages(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("images", flag.ExitOnError)
+	snapshotFile := flags.String("snapshot", "", "Snapshot JSON file")
+	version := flags.String("version", "", "Released version, used as image tag")
+	targetRegistry := flags.String("target-registry", konflux.DefaultReleaseRegistry, "Registry the images are released to")
+	resultsDir := flags.String("results-dir", "", "Directory to write the component-image, commit-sha and git-url results to")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *snapshotFile == "" || *version == "" {
+		return fmt.Errorf("--snapshot and --version are required")
+	}
+
+	snapshot, err := release.ReadSnapshot(*snapshotFile)
+	if err != nil {
+		return err
+	}
+	component, err := snapshot.ReleasedComponent()
+	if err != nil {
+		return err
+	}
+	target, err := release.TargetImage(component.ContainerImage, *targetRegistry)
+	if err != nil {
+		return err
+	}
+	tags := release.Tags(*version, target)
+	log.Printf("Copying %s to %s with tags %v", component.ContainerImage, target.Context(), tags)
+	if err := release.CopyImage(ctx, component.ContainerImage, target, tags); err != nil {
+		return err
+	}
+
+	if *resultsDir == "" {
+		return nil
+	}
+	results := map[string]string{
+		"component-image": target.String(),
+		"commit-sha":      component.Source.Git.Revision,
+		"git-url":         component.Source.Git.URL,
+	}
+	for name, value := range results {
+		if err := os.WriteFile(filepath.Join(*resultsDir, name), []byte(value), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func publishGitHubRelease(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("github", flag.ExitOnError)
+	gitURL := flags.String("git-url", "", "URL of the released repository (https://github.com/<owner>/<repo>)")
+	tag := flags.String("tag", "", "Release tag")
+	target := flags.String("target", "", "Commit the release tag is created on")
+	assetsDir := flags.String("assets", "", "Directory of the files to upload as release assets")
+	githubAPI := flags.String("github-api", forge.DefaultGitHubURL, "GitHub API endpoint")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *gitURL == "" || *tag == "" || *target == "" {
+		return fmt.Errorf("--git-url, --tag and --target are required")
+	}
+	repo, err := repositoryFromURL(*gitURL)
+	if err != nil {
+		return err
+	}
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	client := forge.NewGitHub(*githubAPI, token)
+
+	log.Printf("Creating release %s of %s on %s", *tag, repo, *target)
+	r, err := forge.EnsureRelease(ctx, client, repo, forge.Release{
+		TagName:              *tag,
+		TargetCommitish:      *target,
+		Name:                 *tag,
+		Body:                 fmt.Sprintf("Auto-created release for %s", *tag),
+		GenerateReleaseNotes: true,
+	})
+	if err != nil {
+		return err
+	}
+	if *assetsDir == "" {
+		return nil
+	}
+	if _, err := os.Stat(*assetsDir); errors.Is(err, os.ErrNotExist) {
+		log.Printf("No assets to upload, %s doesn't exist", *assetsDir)
+		return nil
+	}
+	assets, err := listAssets(*assetsDir)
+	if err != nil {
+		return err
+	}
+	for _, asset := range assets {
+		content, err := os.ReadFile(asset)
+		if err != nil {
+			return err
+		}
+		log.Printf("Uploading %s", asset)
+		if _, err := forge.EnsureReleaseAsset(ctx, client, repo, *r, filepath.Base(asset), content); err != nil {
+			return err
+		}
+	}
+	log.Printf("Released %s", r.URL)
+	return nil
+}
+
+// repositoryFromURL returns the owner/repo of a GitHub repository URL.
+func repositoryFromURL(gitURL string) (string, error) {
+	u, err := url.Parse(gitURL)
+	if err != nil {
+		return "", err
+	}
+	repo := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	if strings.Count(repo, "/") != 1 {
+		return "", fmt.Errorf("%s is not a repository URL", gitURL)
+	}
+	return repo, nil
+}
+
+// listAssets returns the files of dir and its subdirectories. The assets are uploaded under their base
+// name, so two files with the same name in different directories are rejected.
+func listAssets(dir string) ([]string, error) {
+	assets := []string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			assets = append(assets, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(assets)
+	names := map[string]string{}
+	for _, asset := range assets {
+		name := filepath.Base(asset)
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("assets %s and %s would both be uploaded as %s", other, asset, name)
+		}
+		names[name] = asset
+	}
+	return assets, nil
+}
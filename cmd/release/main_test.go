@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestListAssets(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"tkn-linux-amd64.tar.gz", "checksums.txt", "linux/tkn-linux-arm64.tar.gz"} {
+		path := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(f), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assets, err := listAssets(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(dir, "checksums.txt"),
+		filepath.Join(dir, "linux", "tkn-linux-arm64.tar.gz"),
+		filepath.Join(dir, "tkn-linux-amd64.tar.gz"),
+	}
+	if !reflect.DeepEqual(assets, want) {
+		t.Errorf("assets = %v, want %v", assets, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "linux", "checksums.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = listAssets(dir)
+	if err == nil || !strings.Contains(err.Error(), "would both be uploaded as checksums.txt") {
+		t.Errorf("expected a duplicate asset error, got %v", err)
+	}
+}
+
+func TestRepositoryFromURL(t *testing.T) {
+	for url, want := range map[string]string{
+		"https://github.com/openshift-pipelines/tektoncd-cli":      "openshift-pipelines/tektoncd-cli",
+		"https://github.com/openshift-pipelines/tektoncd-cli.git/": "openshift-pipelines/tektoncd-cli",
+	} {
+		got, err := repositoryFromURL(url)
+		if err != nil || got != want {
+			t.Errorf("repositoryFromURL(%s) = %s, %v, want %s", url, got, err, want)
+		}
+	}
+	if _, err := repositoryFromURL("https://github.com/openshift-pipelines"); err == nil {
+		t.Error("expected an error for an organization URL")
+	}
+}
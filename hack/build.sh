@@ -0,0 +1,34 @@
+#!/bin/bash
+
+set -euo pipefail
+
+# Builds the release command and its image, run by the build-and-release workflow
+#
+# The image is pushed as $DOCKER_REPO/release:$VERSION and its digest is written to
+# builds/release-image.txt, which is the release-image to set in config/*/releases/<version>.yaml
+# so that the release pipeline runs this build.
+#
+# Usage: DOCKER_REPO=ghcr.io/openshift-pipelines-konflux/hack [VERSION=v0.1.0] ./hack/build.sh
+
+cd "$(dirname "$0")/.."
+
+VERSION=${VERSION:-v0.0.0-$(date -u +%Y%m%d%H%M%S)-$(git rev-parse --short=12 HEAD)}
+IMAGE="${DOCKER_REPO:?DOCKER_REPO is required}/release:${VERSION}"
+
+rm -rf builds
+mkdir -p builds
+for platform in linux/amd64 linux/arm64 darwin/amd64 darwin/arm64; do
+    CGO_ENABLED=0 GOOS=${platform%/*} GOARCH=${platform#*/} \
+        go build -trimpath -o "builds/release-${platform%/*}-${platform#*/}" ./cmd/release
+done
+
+docker build -f cmd/release/Dockerfile -t "$IMAGE" .
+docker push "$IMAGE"
+DIGEST=$(docker inspect --format '{{index .RepoDigests 0}}' "$IMAGE")
+echo "${IMAGE}@${DIGEST#*@}" > builds/release-image.txt
+echo "Pushed $(cat builds/release-image.txt)"
+
+# The workflow tags and releases $VERSION
+if [ -n "${GITHUB_ENV:-}" ]; then
+    echo "VERSION=${VERSION}" >> "$GITHUB_ENV"
+fi
@@ -1,6 +1,6 @@
 #!/bin/bash
 
-set -e
+set -eo pipefail
 
 # One command to update everything with simplified parameters
 # 
@@ -22,6 +22,9 @@ set -e
 # Options:
 #   --dry-run        - Show what would be changed without actually making changes
 
+# Paths are relative to the repository root
+cd "$(dirname "$0")/.."
+
 # Parse arguments
 DRY_RUN=""
 if [ "$1" == "--dry-run" ]; then
@@ -41,13 +44,16 @@ NEW_VERSION=$1
 IMAGE_SUFFIX=$2
 OLD_VERSION="next"
 
-# Check if the version includes a patch version (e.g., 0.5.0)
-if [[ "$NEW_VERSION" =~ ^[0-9]+\.[0-9]+\.[0-9]+$ ]]; then
-    # For versions like 0.5.0, use release-v0.5.0
-    BRANCH_PATTERN="release-v$NEW_VERSION"
-else
-    # For versions like 0.5, use release-v0.5.x
-    BRANCH_PATTERN="release-v$NEW_VERSION.x"
+# The konflux command is built once and reused
+BUILD_DIR=$(mktemp -d)
+trap 'rm -rf "$BUILD_DIR"' EXIT
+KONFLUX="$BUILD_DIR/konflux"
+go build -o "$KONFLUX" ./cmd/konflux
+
+# The branch of the version (release-v0.5.x for 0.5, release-v0.5.0 for 0.5.0), as named by the konflux command
+if ! BRANCH_PATTERN=$("$KONFLUX" release branch "$NEW_VERSION") || [ -z "$BRANCH_PATTERN" ]; then
+    echo "Error: Unable to find the branch of version $NEW_VERSION"
+    exit 1
 fi
 
 echo "==================================================================="
@@ -78,7 +84,7 @@ fi
 echo ""
 
 # Call the main update script with our parameters
-./hack/update-configs.sh $DRY_RUN $OLD_VERSION $NEW_VERSION $IMAGE_SUFFIX $BRANCH_PATTERN
+./hack/update-configs.sh $DRY_RUN "$OLD_VERSION" "$NEW_VERSION" "$IMAGE_SUFFIX" "$BRANCH_PATTERN"
 
 echo ""
 echo "==================================================================="
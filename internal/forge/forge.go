@@ -0,0 +1,102 @@
+package forge
+
+import (
+	"context"
+)
+
+// PullRequest is a pull-request (or merge-request) on a forge.
+type PullRequest struct {
+	Number int    `json:"number,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Body   string `json:"body,omitempty"`
+	// Head is the source branch, prefixed by the owner of the fork if any (owner:branch).
+	Head string `json:"head,omitempty"`
+	Base string `json:"base,omitempty"`
+	URL  string `json:"html_url,omitempty"`
+}
+
+// Client is the subset of the forge API used by the hack tools.
+// It's an interface so that a fake can stand in for the real forge.
+type Client interface {
+	// FindPullRequest returns the open pull-request from head to base, or nil if there is none.
+	FindPullRequest(ctx context.Context, repo string, head string, base string) (*PullRequest, error)
+	CreatePullRequest(ctx context.Context, repo string, pr PullRequest) (*PullRequest, error)
+	UpdatePullRequest(ctx context.Context, repo string, pr PullRequest) (*PullRequest, error)
+}
+
+// EnsurePullRequest creates the pull-request, or updates the title and body of the existing one.
+func EnsurePullRequest(ctx context.Context, c Client, repo string, pr PullRequest) (*PullRequest, error) {
+	existing, err := c.FindPullRequest(ctx, repo, pr.Head, pr.Base)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreatePullRequest(ctx, repo, pr)
+	}
+	pr.Number = existing.Number
+	return c.UpdatePullRequest(ctx, repo, pr)
+}
+
+// Release is a release of a repository, identified by its tag.
+type Release struct {
+	ID      int64  `json:"id,omitempty"`
+	TagName string `json:"tag_name,omitempty"`
+	// TargetCommitish is the commit the tag is created on, if it doesn't exist yet.
+	TargetCommitish      string  `json:"target_commitish,omitempty"`
+	Name                 string  `json:"name,omitempty"`
+	Body                 string  `json:"body,omitempty"`
+	GenerateReleaseNotes bool    `json:"generate_release_notes,omitempty"`
+	UploadURL            string  `json:"upload_url,omitempty"`
+	URL                  string  `json:"html_url,omitempty"`
+	Assets               []Asset `json:"assets,omitempty"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// ReleaseClient is the subset of the forge API used to publish releases.
+type ReleaseClient interface {
+	// GetReleaseByTag returns the release of the tag, or nil if there is none.
+	GetReleaseByTag(ctx context.Context, repo string, tag string) (*Release, error)
+	CreateRelease(ctx context.Context, repo string, release Release) (*Release, error)
+	UpdateRelease(ctx context.Context, repo string, release Release) (*Release, error)
+	// DeleteRelease deletes the release and its tag.
+	DeleteRelease(ctx context.Context, repo string, release Release) error
+	UploadReleaseAsset(ctx context.Context, repo string, release Release, name string, content []byte) (*Asset, error)
+	DeleteReleaseAsset(ctx context.Context, repo string, asset Asset) error
+}
+
+// EnsureRelease creates the release, or updates the existing one. A release targeting another commit
+// is deleted along with its tag and created again, so that the tag points to the released commit.
+func EnsureRelease(ctx context.Context, c ReleaseClient, repo string, release Release) (*Release, error) {
+	existing, err := c.GetReleaseByTag(ctx, repo, release.TagName)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateRelease(ctx, repo, release)
+	}
+	if existing.TargetCommitish != release.TargetCommitish {
+		if err := c.DeleteRelease(ctx, repo, *existing); err != nil {
+			return nil, err
+		}
+		return c.CreateRelease(ctx, repo, release)
+	}
+	release.ID = existing.ID
+	return c.UpdateRelease(ctx, repo, release)
+}
+
+// EnsureReleaseAsset uploads the asset, replacing the existing asset with the same name.
+func EnsureReleaseAsset(ctx context.Context, c ReleaseClient, repo string, release Release, name string, content []byte) (*Asset, error) {
+	for _, asset := range release.Assets {
+		if asset.Name == name {
+			if err := c.DeleteReleaseAsset(ctx, repo, asset); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return c.UploadReleaseAsset(ctx, repo, release, name, content)
+}
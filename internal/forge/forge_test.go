@@ -0,0 +1,219 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fakeReleases is an httptest handler serving the releases endpoints of the GitHub API for a repository.
+type fakeReleases struct {
+	t        *testing.T
+	server   *httptest.Server
+	repo     string
+	nextID   int64
+	releases map[int64]*Release
+	tags     map[string]bool
+	// requests records the method and path of each request.
+	requests []string
+}
+
+func newFakeReleases(t *testing.T, repo string) *fakeReleases {
+	f := &fakeReleases{t: t, repo: repo, releases: map[int64]*Release{}, tags: map[string]bool{}}
+	f.server = httptest.NewServer(f)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeReleases) add(release Release) *Release {
+	f.nextID++
+	release.ID = f.nextID
+	release.UploadURL = fmt.Sprintf("%s/uploads/%d{?name,label}", f.server.URL, release.ID)
+	release.URL = fmt.Sprintf("https://github.com/%s/releases/tag/%s", f.repo, release.TagName)
+	f.releases[release.ID] = &release
+	f.tags[release.TagName] = true
+	return &release
+}
+
+func (f *fakeReleases) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/repos/"+f.repo)
+	f.requests = append(f.requests, r.Method+" "+path)
+	body, _ := io.ReadAll(r.Body)
+	release := Release{}
+	if len(body) > 0 && r.Header.Get("Content-Type") == "application/json" {
+		if err := json.Unmarshal(body, &release); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	reply := func(status int, v interface{}) {
+		w.WriteHeader(status)
+		if v != nil {
+			_ = json.NewEncoder(w).Encode(v)
+		}
+	}
+	id := func(prefix string) *Release {
+		n, _ := strconv.ParseInt(strings.TrimPrefix(path, prefix), 10, 64)
+		return f.releases[n]
+	}
+
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/releases/tags/"):
+		for _, existing := range f.releases {
+			if existing.TagName == strings.TrimPrefix(path, "/releases/tags/") {
+				reply(http.StatusOK, existing)
+				return
+			}
+		}
+		reply(http.StatusNotFound, map[string]string{"message": "Not Found"})
+	case r.Method == http.MethodPost && path == "/releases":
+		reply(http.StatusCreated, f.add(release))
+	case r.Method == http.MethodPatch && strings.HasPrefix(path, "/releases/"):
+		existing := id("/releases/")
+		if existing == nil {
+			reply(http.StatusNotFound, nil)
+			return
+		}
+		existing.TagName, existing.Name, existing.Body = release.TagName, release.Name, release.Body
+		reply(http.StatusOK, existing)
+	case r.Method == http.MethodDelete && strings.HasPrefix(path, "/releases/assets/"):
+		n, _ := strconv.ParseInt(strings.TrimPrefix(path, "/releases/assets/"), 10, 64)
+		for _, existing := range f.releases {
+			for i, asset := range existing.Assets {
+				if asset.ID == n {
+					existing.Assets = append(existing.Assets[:i], existing.Assets[i+1:]...)
+					reply(http.StatusNoContent, nil)
+					return
+				}
+			}
+		}
+		reply(http.StatusNotFound, nil)
+	case r.Method == http.MethodDelete && strings.HasPrefix(path, "/releases/"):
+		existing := id("/releases/")
+		if existing == nil {
+			reply(http.StatusNotFound, nil)
+			return
+		}
+		delete(f.releases, existing.ID)
+		reply(http.StatusNoContent, nil)
+	case r.Method == http.MethodDelete && strings.HasPrefix(path, "/git/refs/tags/"):
+		tag := strings.TrimPrefix(path, "/git/refs/tags/")
+		if !f.tags[tag] {
+			reply(http.StatusNotFound, nil)
+			return
+		}
+		delete(f.tags, tag)
+		reply(http.StatusNoContent, nil)
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/uploads/"):
+		existing := id("/uploads/")
+		if existing == nil {
+			reply(http.StatusNotFound, nil)
+			return
+		}
+		for _, asset := range existing.Assets {
+			if asset.Name == r.URL.Query().Get("name") {
+				reply(http.StatusUnprocessableEntity, map[string]string{"message": "already_exists"})
+				return
+			}
+		}
+		f.nextID++
+		asset := Asset{ID: f.nextID, Name: r.URL.Query().Get("name")}
+		existing.Assets = append(existing.Assets, asset)
+		reply(http.StatusCreated, asset)
+	default:
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		reply(http.StatusMethodNotAllowed, nil)
+	}
+}
+
+func TestEnsureRelease(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeReleases(t, "openshift-pipelines/tektoncd-cli")
+	client := NewGitHub(fake.server.URL, "token")
+	repo := "openshift-pipelines/tektoncd-cli"
+
+	// Created
+	created, err := EnsureRelease(ctx, client, repo, Release{TagName: "1.15.1", TargetCommitish: "abc", Name: "1.15.1", Body: "first"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == 0 || created.TargetCommitish != "abc" || len(fake.releases) != 1 {
+		t.Fatalf("release not created: %+v", created)
+	}
+
+	// Updated, on the same commit
+	updated, err := EnsureRelease(ctx, client, repo, Release{TagName: "1.15.1", TargetCommitish: "abc", Name: "1.15.1", Body: "second"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.ID != created.ID || updated.Body != "second" {
+		t.Errorf("release not updated: %+v", updated)
+	}
+
+	// Recreated, with its tag, on another commit
+	recreated, err := EnsureRelease(ctx, client, repo, Release{TagName: "1.15.1", TargetCommitish: "def", Name: "1.15.1", Body: "third"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recreated.ID == created.ID || recreated.TargetCommitish != "def" || len(fake.releases) != 1 {
+		t.Errorf("release not recreated: %+v", recreated)
+	}
+
+	want := []string{
+		"GET /releases/tags/1.15.1", "POST /releases",
+		"GET /releases/tags/1.15.1", fmt.Sprintf("PATCH /releases/%d", created.ID),
+		"GET /releases/tags/1.15.1", fmt.Sprintf("DELETE /releases/%d", created.ID), "DELETE /git/refs/tags/1.15.1", "POST /releases",
+	}
+	if !reflect.DeepEqual(fake.requests, want) {
+		t.Errorf("requests = %v, want %v", fake.requests, want)
+	}
+}
+
+func TestEnsureReleaseAsset(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeReleases(t, "openshift-pipelines/tektoncd-cli")
+	client := NewGitHub(fake.server.URL, "token")
+	repo := "openshift-pipelines/tektoncd-cli"
+
+	release := fake.add(Release{TagName: "1.15.1", TargetCommitish: "abc"})
+	asset, err := EnsureReleaseAsset(ctx, client, repo, *release, "checksums.txt", []byte("1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asset.Name != "checksums.txt" || len(fake.releases[release.ID].Assets) != 1 {
+		t.Fatalf("asset not uploaded: %+v", asset)
+	}
+
+	// The existing asset is replaced
+	existing, err := client.GetReleaseByTag(ctx, repo, "1.15.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	replaced, err := EnsureReleaseAsset(ctx, client, repo, *existing, "checksums.txt", []byte("2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assets := fake.releases[release.ID].Assets; len(assets) != 1 || assets[0].ID != replaced.ID || replaced.ID == asset.ID {
+		t.Errorf("asset not replaced: %+v", assets)
+	}
+
+	// A release without upload URL is rejected
+	if _, err := EnsureReleaseAsset(ctx, client, repo, Release{TagName: "1.15.1"}, "checksums.txt", nil); err == nil {
+		t.Error("expected an error without upload URL")
+	}
+}
+
+func TestGetReleaseByTagNotFound(t *testing.T) {
+	fake := newFakeReleases(t, "openshift-pipelines/tektoncd-cli")
+	release, err := NewGitHub(fake.server.URL, "").GetReleaseByTag(context.Background(), "openshift-pipelines/tektoncd-cli", "1.15.1")
+	if err != nil || release != nil {
+		t.Errorf("expected no release, got %v, %v", release, err)
+	}
+}
@@ -0,0 +1,191 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const DefaultGitHubURL = "https://api.github.com"
+
+// GitHub is a Client for the GitHub REST API.
+type GitHub struct {
+	// BaseURL is the API endpoint, it can point to a local server in tests.
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+func NewGitHub(baseURL string, token string) *GitHub {
+	if baseURL == "" {
+		baseURL = DefaultGitHubURL
+	}
+	return &GitHub{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Token:      token,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+func (g *GitHub) FindPullRequest(ctx context.Context, repo string, head string, base string) (*PullRequest, error) {
+	query := url.Values{}
+	query.Set("state", "open")
+	query.Set("head", head)
+	query.Set("base", base)
+	prs := []PullRequest{}
+	if err := g.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/pulls?%s", repo, query.Encode()), nil, &prs); err != nil {
+		return nil, err
+	}
+	if len(prs) == 0 {
+		return nil, nil
+	}
+	return &prs[0], nil
+}
+
+func (g *GitHub) CreatePullRequest(ctx context.Context, repo string, pr PullRequest) (*PullRequest, error) {
+	created := &PullRequest{}
+	if err := g.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/pulls", repo), pr, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (g *GitHub) UpdatePullRequest(ctx context.Context, repo string, pr PullRequest) (*PullRequest, error) {
+	updated := &PullRequest{}
+	body := PullRequest{Title: pr.Title, Body: pr.Body}
+	if err := g.do(ctx, http.MethodPatch, fmt.Sprintf("/repos/%s/pulls/%d", repo, pr.Number), body, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (g *GitHub) GetReleaseByTag(ctx context.Context, repo string, tag string) (*Release, error) {
+	release := &Release{}
+	err := g.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/releases/tags/%s", repo, url.PathEscape(tag)), nil, release)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+func (g *GitHub) CreateRelease(ctx context.Context, repo string, release Release) (*Release, error) {
+	created := &Release{}
+	if err := g.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/releases", repo), release, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (g *GitHub) UpdateRelease(ctx context.Context, repo string, release Release) (*Release, error) {
+	updated := &Release{}
+	body := Release{TagName: release.TagName, Name: release.Name, Body: release.Body}
+	if err := g.do(ctx, http.MethodPatch, fmt.Sprintf("/repos/%s/releases/%d", repo, release.ID), body, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (g *GitHub) DeleteRelease(ctx context.Context, repo string, release Release) error {
+	if err := g.do(ctx, http.MethodDelete, fmt.Sprintf("/repos/%s/releases/%d", repo, release.ID), nil, nil); err != nil {
+		return err
+	}
+	err := g.do(ctx, http.MethodDelete, fmt.Sprintf("/repos/%s/git/refs/tags/%s", repo, url.PathEscape(release.TagName)), nil, nil)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (g *GitHub) UploadReleaseAsset(ctx context.Context, repo string, release Release, name string, content []byte) (*Asset, error) {
+	// The upload_url is a URI template: https://uploads.github.com/repos/o/r/releases/1/assets{?name,label}
+	uploadURL, _, _ := strings.Cut(release.UploadURL, "{")
+	if uploadURL == "" {
+		return nil, fmt.Errorf("release %s has no upload URL", release.TagName)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL+"?name="+url.QueryEscape(name), bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	asset := &Asset{}
+	if err := g.send(req, asset); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+func (g *GitHub) DeleteReleaseAsset(ctx context.Context, repo string, asset Asset) error {
+	return g.do(ctx, http.MethodDelete, fmt.Sprintf("/repos/%s/releases/assets/%d", repo, asset.ID), nil, nil)
+}
+
+// StatusError is returned when the API responds with an unexpected status.
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s: unexpected status %s: %s", e.Method, e.URL, e.Status, e.Body)
+}
+
+// IsNotFound reports whether err is a 404 response of the API.
+func IsNotFound(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
+
+func (g *GitHub) do(ctx context.Context, method string, path string, in interface{}, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, g.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return g.send(req, out)
+}
+
+func (g *GitHub) send(req *http.Request, out interface{}) error {
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if g.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.Token)
+	}
+
+	resp, err := g.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", req.Method, req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &StatusError{Method: req.Method, URL: req.URL.Path, StatusCode: resp.StatusCode, Status: resp.Status, Body: string(b)}
+	}
+	if out == nil || len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, out)
+}
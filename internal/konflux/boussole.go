@@ -0,0 +1,122 @@
+package konflux
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// boussoleCommands are the commands handled by boussole, and whether they take arguments.
+var boussoleCommands = []struct {
+	Name string
+	Args bool
+}{
+	{"help", false},
+	{"rebase", false},
+	{"merge", false},
+	{"lgtm", false},
+	{"cherry-pick", true},
+	{"assign", true},
+	{"unassign", true},
+	{"label", true},
+	{"unlabel", true},
+}
+
+// defaultBoussoleParams are the optional parameters of the boussole pipeline set when not configured.
+var defaultBoussoleParams = []Param{
+	{Name: "lgtm_threshold", Value: "1"},
+	{Name: "lgtm_permissions", Value: "admin,write"},
+	{Name: "merge_method", Value: "rebase"},
+}
+
+// BoussoleView is the data of the boussole PipelineRun template.
+type BoussoleView struct {
+	generated
+	Repository string
+	// OnComment is the pipelinesascode.tekton.dev/on-comment regular expression of the allowed commands.
+	OnComment string
+	Params    []Param
+}
+
+// AssignBoussoleOwners makes the first application configuring the boussole of a repository branch the only
+// one rendering it, as the repositories can be shared by several applications (tektoncd-operator is part of
+// the core and index applications). The other applications must configure it the same way, or not at all.
+func AssignBoussoleOwners(applications []Application) error {
+	owners := map[string]*Repository{}
+	for i := range applications {
+		for j := range applications[i].Repositories {
+			repo := &applications[i].Repositories[j]
+			if repo.Boussole == nil {
+				continue
+			}
+			key := repo.Url + "@" + repo.Branch.Name
+			owner, ok := owners[key]
+			if !ok {
+				owners[key] = repo
+				continue
+			}
+			if !reflect.DeepEqual(*owner.Boussole, *repo.Boussole) {
+				return fmt.Errorf("repository %s (branch %s) has different boussole configurations in applications %s and %s",
+					repo.Name, repo.Branch.Name, owner.Application.Name, applications[i].Name)
+			}
+			repo.boussoleOwner = owner.Application.Name
+		}
+	}
+	return nil
+}
+
+func newBoussoleView(r Repository) (BoussoleView, error) {
+	onComment, err := boussoleOnComment(r.Boussole.Commands)
+	if err != nil {
+		return BoussoleView{}, fmt.Errorf("boussole of repository %s: %w", r.Name, err)
+	}
+	params := append([]Param{}, r.Boussole.Params...)
+	for _, d := range defaultBoussoleParams {
+		if !hasParam(params, d.Name) {
+			params = append(params, d)
+		}
+	}
+	return BoussoleView{
+		generated:  generated{ApplicationName: r.Application.Name, templateDir: r.Application.TemplateDir, overrides: r.Templates},
+		Repository: r.Name,
+		OnComment:  onComment,
+		Params:     params,
+	}, nil
+}
+
+// boussoleOnComment returns the regular expression matching the allowed commands, e.g.
+// ^/(help|lgtm|(cherry-pick|label)[ ].*)$.
+func boussoleOnComment(commands []string) (string, error) {
+	allowed := map[string]bool{}
+	for _, c := range commands {
+		allowed[strings.TrimPrefix(c, "/")] = true
+	}
+	var simple, withArgs []string
+	for _, c := range boussoleCommands {
+		if len(commands) > 0 && !allowed[c.Name] {
+			continue
+		}
+		delete(allowed, c.Name)
+		if c.Args {
+			withArgs = append(withArgs, c.Name)
+		} else {
+			simple = append(simple, c.Name)
+		}
+	}
+	for c := range allowed {
+		return "", fmt.Errorf("unknown command %q", c)
+	}
+	if len(withArgs) > 0 {
+		simple = append(simple, "("+strings.Join(withArgs, "|")+")[ ].*")
+	}
+	return "^/(" + strings.Join(simple, "|") + ")$", nil
+}
+
+func hasParam(params []Param, name string) bool {
+	for _, p := range params {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
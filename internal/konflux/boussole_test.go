@@ -0,0 +1,85 @@
+package konflux
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// sharedRepositoryApplications returns two applications sharing the same repository, with their boussole.
+func sharedRepositoryApplications(t *testing.T, core, index *Boussole) []Application {
+	t.Helper()
+	applications := []Application{}
+	for _, a := range []struct {
+		name     string
+		boussole *Boussole
+	}{{"core", core}, {"index-4.15", index}} {
+		application := &Application{Name: a.name}
+		application.Repositories = []Repository{{
+			Name:        "tektoncd-operator",
+			Url:         "https://github.com/openshift-pipelines/tektoncd-operator.git",
+			Branch:      Branch{Name: "release-v1.15.x"},
+			Application: application,
+			Boussole:    a.boussole,
+		}}
+		applications = append(applications, *application)
+	}
+	return applications
+}
+
+func TestAssignBoussoleOwners(t *testing.T) {
+	boussole := func() *Boussole {
+		return &Boussole{Enabled: true, Commands: []string{"lgtm", "merge"}}
+	}
+	for _, tt := range []struct {
+		name        string
+		core, index *Boussole
+	}{
+		{"same configuration", boussole(), boussole()},
+		{"configured once", boussole(), nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			applications := sharedRepositoryApplications(t, tt.core, tt.index)
+			if err := AssignBoussoleOwners(applications); err != nil {
+				t.Fatal(err)
+			}
+			// Only the first application renders the PipelineRun
+			for i, want := range []bool{true, false} {
+				dir := t.TempDir()
+				if err := generateTektonConfig(applications[i].Repositories[0], dir); err != nil {
+					t.Fatal(err)
+				}
+				content, err := os.ReadFile(filepath.Join(dir, tektonDir, "boussole.yaml"))
+				if rendered := err == nil; rendered != want {
+					t.Errorf("application %s: boussole rendered %v, want %v", applications[i].Name, rendered, want)
+				}
+				if want && !strings.HasPrefix(string(content), "# Generated for Konflux Application core ") {
+					t.Errorf("unexpected header:\n%s", content)
+				}
+			}
+		})
+	}
+
+	// Another branch of the repository is rendered by each application
+	applications := sharedRepositoryApplications(t, boussole(), boussole())
+	applications[1].Repositories[0].Branch.Name = "release-v1.16.x"
+	if err := AssignBoussoleOwners(applications); err != nil {
+		t.Fatal(err)
+	}
+	if owner := applications[1].Repositories[0].boussoleOwner; owner != "" {
+		t.Errorf("unexpected owner %s of another branch", owner)
+	}
+}
+
+func TestAssignBoussoleOwnersConflict(t *testing.T) {
+	other := &Boussole{Enabled: true, Params: []Param{{Name: "merge_method", Value: "squash"}}}
+	for _, index := range []*Boussole{other, {Enabled: false}} {
+		applications := sharedRepositoryApplications(t, &Boussole{Enabled: true}, index)
+		err := AssignBoussoleOwners(applications)
+		want := "repository tektoncd-operator (branch release-v1.15.x) has different boussole configurations in applications core and index-4.15"
+		if err == nil || err.Error() != want {
+			t.Errorf("expected a conflict error, got %v", err)
+		}
+	}
+}
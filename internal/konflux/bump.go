@@ -0,0 +1,41 @@
+package konflux
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ReleaseChecker reports whether a patch version of an application was released.
+type ReleaseChecker interface {
+	Released(ctx context.Context, application Application, patchVersion ReleaseVersion) (bool, error)
+}
+
+// BumpPatchVersion returns the next patch version of the applications of a release, refusing to bump
+// when the current patch version was not released for all of them.
+func BumpPatchVersion(ctx context.Context, checker ReleaseChecker, applications []Application) (ReleaseVersion, error) {
+	if len(applications) == 0 {
+		return ReleaseVersion{}, fmt.Errorf("no application to bump")
+	}
+	current, err := applications[0].Release.Patch()
+	if err != nil {
+		return ReleaseVersion{}, err
+	}
+	notReleased := []string{}
+	for _, application := range applications {
+		if application.Release.PatchVersion != current.String() {
+			return ReleaseVersion{}, fmt.Errorf("application %s is at %s, expected %s", application.Name, application.Release.PatchVersion, current)
+		}
+		released, err := checker.Released(ctx, application, current)
+		if err != nil {
+			return ReleaseVersion{}, fmt.Errorf("failed to check the release %s of %s: %w", current, application.Name, err)
+		}
+		if !released {
+			notReleased = append(notReleased, application.Name)
+		}
+	}
+	if len(notReleased) > 0 {
+		return ReleaseVersion{}, fmt.Errorf("%s was never released for %s", current, strings.Join(notReleased, ", "))
+	}
+	return current.NextPatch()
+}
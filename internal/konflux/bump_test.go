@@ -0,0 +1,100 @@
+package konflux
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeChecker reports the patch versions released per application.
+type fakeChecker struct {
+	released map[string][]string
+	err      error
+	checked  []string
+}
+
+func (f *fakeChecker) Released(_ context.Context, application Application, patchVersion ReleaseVersion) (bool, error) {
+	f.checked = append(f.checked, application.Name+"@"+patchVersion.String())
+	if f.err != nil {
+		return false, f.err
+	}
+	for _, v := range f.released[application.Name] {
+		if v == patchVersion.String() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func testApplications(t *testing.T, patchVersions ...string) []Application {
+	t.Helper()
+	applications := []Application{}
+	for i, patchVersion := range patchVersions {
+		application := *testApplication(t)
+		application.Name = []string{"pipelines", "pipelines-index-4.15", "pipelines-index-4.16"}[i]
+		release := *application.Release
+		release.PatchVersion = patchVersion
+		application.Release = &release
+		applications = append(applications, application)
+	}
+	return applications
+}
+
+func TestBumpPatchVersion(t *testing.T) {
+	ctx := context.Background()
+	applications := testApplications(t, "1.15.1", "1.15.1")
+
+	// The current patch version is not released for every application
+	checker := &fakeChecker{released: map[string][]string{"pipelines": {"1.15.0", "1.15.1"}, "pipelines-index-4.15": {"1.15.0"}}}
+	_, err := BumpPatchVersion(ctx, checker, applications)
+	if err == nil || err.Error() != "1.15.1 was never released for pipelines-index-4.15" {
+		t.Errorf("expected the bump to be refused, got %v", err)
+	}
+	if want := "pipelines@1.15.1,pipelines-index-4.15@1.15.1"; strings.Join(checker.checked, ",") != want {
+		t.Errorf("checked %v, want %s", checker.checked, want)
+	}
+
+	// Once released, the next patch version is returned
+	checker.released["pipelines-index-4.15"] = append(checker.released["pipelines-index-4.15"], "1.15.1")
+	next, err := BumpPatchVersion(ctx, checker, applications)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.String() != "1.15.2" {
+		t.Errorf("next = %s, want 1.15.2", next)
+	}
+}
+
+func TestBumpPatchVersionErrors(t *testing.T) {
+	ctx := context.Background()
+	released := &fakeChecker{released: map[string][]string{"pipelines": {"1.15.1"}, "pipelines-index-4.15": {"1.15.1"}}}
+	mainVersion, err := ParseReleaseVersion("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	development := testApplications(t, "nightly")
+	development[0].Release.Version = mainVersion
+
+	tests := []struct {
+		name         string
+		applications []Application
+		checker      ReleaseChecker
+		err          string
+	}{
+		{"no application", nil, released, "no application to bump"},
+		{"development version", development, released, "main is a development version"},
+		{"not a patch version", testApplications(t, "1.15"), released, "1.15 is not a patch version of 1.15"},
+		{"patch version of another release", testApplications(t, "1.14.3"), released, "1.14.3 is not a patch version of 1.15"},
+		{"different patch versions", testApplications(t, "1.15.1", "1.15.0"), released, "application pipelines-index-4.15 is at 1.15.0, expected 1.15.1"},
+		{"checker error", testApplications(t, "1.15.1"), &fakeChecker{err: errors.New("unauthorized")}, "failed to check the release 1.15.1 of pipelines: unauthorized"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BumpPatchVersion(ctx, tt.checker, tt.applications)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -4,16 +4,44 @@ type Config struct {
 	Applications []string
 	Versions     []string
 	Repositories []Repository `json:"repos" yaml:"repos"`
+	Tenant       Tenant
+	// ShortenNames shortens the resource names longer than 63 characters with a hash suffix.
+	ShortenNames bool `json:"shorten-names" yaml:"shorten-names"`
+}
+
+// Tenant is the Konflux tenant the configuration is generated for.
+type Tenant struct {
+	// Namespace is the tenant namespace, where the build PipelineRuns are running.
+	Namespace string
+	// Registry is where the images of the components are pushed.
+	Registry string
+	// Policy is the prefix of the enterprise contract policies (<policy>-containers and <policy>-indexes).
+	Policy string
+	// SBOMWebhook is notified of the images pushed in the registry.
+	SBOMWebhook string `json:"sbom-webhook" yaml:"sbom-webhook"`
+	// ReleaseSecret is the pull secret of the release registry.
+	ReleaseSecret string `json:"release-secret" yaml:"release-secret"`
+	// BotName and BotEmail are the git identity of the generated commits.
+	BotName  string `json:"bot-name" yaml:"bot-name"`
+	BotEmail string `json:"bot-email" yaml:"bot-email"`
 }
 
 type Application struct {
-	Name            string
-	Org             string
-	Components      []Component
-	Release         *Release
-	Repositories    []Repository
-	ReleaseToGitHub bool `yaml:"release-to-github"`
-	AutoRelease     bool
+	Name         string
+	Org          string
+	Components   []Component
+	Release      *Release
+	Repositories []Repository
+	// Releases are the release targets, each released by its own ReleasePlan.
+	Releases []ReleaseTarget
+	// TemplateDir is the overlay directory of the embedded templates.
+	TemplateDir  string
+	Tenant       Tenant
+	ShortenNames bool
+	// Tests are the IntegrationTestScenarios of the application.
+	Tests []TestScenario
+	// Boussole is the boussole configuration of the repositories not configuring it.
+	Boussole *Boussole
 }
 
 type Repository struct {
@@ -22,11 +50,29 @@ type Repository struct {
 	Url              string
 	Branch           Branch
 	Components       []Component
-	Application      Application
+	Application      *Application `json:"-" yaml:"-"`
 	Tekton           Tekton
 	GitHub           GitHub
 	Patches          []Patch
 	NoPrefixUpstream bool `json:"no-prefix-upstream" yaml:"no-prefix-upstream"`
+	// Templates overrides the embedded templates (by file name) for this repository.
+	Templates map[string]string
+	// Boussole configures the boussole ChatOps PipelineRun, the application one if not set.
+	Boussole *Boussole
+	// boussoleOwner is the application rendering the boussole PipelineRun of a repository shared by
+	// several applications, empty when it's this one.
+	boussoleOwner string
+}
+
+// Boussole is the pac-boussole PipelineRun handling the /lgtm, /merge, /cherry-pick, ... comments of the
+// pull requests, rendered in .tekton when enabled.
+type Boussole struct {
+	Enabled bool
+	// Commands are the allowed commands, all of them if empty.
+	Commands []string
+	// Params are the optional parameters of the boussole pipeline (lgtm_threshold, merge_method, ...),
+	// overriding the default ones.
+	Params []Param
 }
 type Branch struct {
 	Name           string
@@ -42,10 +88,76 @@ type Component struct {
 	ImageSuffix   string `json:"image-suffix" yaml:"image-suffix"`
 	PrefetchInput string `json:"prefetch-input" yaml:"prefetch-input"`
 	Version       Release
-	Repository    Repository
-	Application   Application
+	Repository    *Repository  `json:"-" yaml:"-"`
+	Application   *Application `json:"-" yaml:"-"`
 	Tekton        Tekton
 	NoImagePrefix bool `json:"no-image-prefix" yaml:"no-image-prefix"`
+	Build         Build
+}
+
+const (
+	BuildKindContainer = "container"
+	BuildKindFBC       = "fbc"
+	BuildKindBundle    = "bundle"
+)
+
+// Build configures the build PipelineRuns of a component.
+type Build struct {
+	// Kind is the kind of build pipeline: container, fbc or bundle.
+	Kind string
+	// Platforms are the build-platforms, the pipeline default is used for push if empty.
+	Platforms []string
+	// PipelineURL is the pipeline resolved by Pipelines as Code, it defaults to the docker build pipeline
+	// (the FBC build pipeline for the FBC components of the release branches).
+	PipelineURL     string `json:"pipeline-url" yaml:"pipeline-url"`
+	BuildImageIndex *bool  `json:"build-image-index" yaml:"build-image-index"`
+	Params          []Param
+}
+
+type Param struct {
+	Name  string
+	Value string
+}
+
+// PipelineName returns the name of the build pipeline for the kind of build.
+func (b Build) PipelineName() string {
+	if b.Kind == BuildKindFBC {
+		return "fbc-build"
+	}
+	return "docker-build-ta"
+}
+
+const (
+	TestScenarioEnterpriseContract = "enterprise-contract"
+	TestScenarioBundleE2E          = "bundle-e2e"
+	TestScenarioPipeline           = "pipeline"
+)
+
+// TestScenario is an IntegrationTestScenario of an application.
+type TestScenario struct {
+	// Name is appended to the application resource name to name the scenario.
+	Name string
+	// Type is enterprise-contract, bundle-e2e (the operator bundle end-to-end tests) or pipeline.
+	Type string
+	// Policy is the enterprise contract policy, the tenant policy for containers or indexes if empty.
+	Policy string
+	// Pipeline is the test pipeline, resolved from git.
+	Pipeline GitResolver
+	Params   []Param
+	// Contexts are the Snapshots the scenario runs for, every Snapshot if empty.
+	Contexts []TestContext
+}
+
+// GitResolver is a pipeline resolved by the Tekton git resolver.
+type GitResolver struct {
+	URL        string
+	Revision   string
+	PathInRepo string `json:"path-in-repo" yaml:"path-in-repo"`
+}
+
+type TestContext struct {
+	Name        string
+	Description string
 }
 
 type Tekton struct {
@@ -54,8 +166,25 @@ type Tekton struct {
 	NudgeFiles     string `json:"build-nudge-files" yaml:"build-nudge-files"`
 }
 
+// GitHub configures the generated GitHub workflows updating the sources from upstream and merging the
+// update pull requests.
 type GitHub struct {
+	// UpdateSources are additional steps of the update-sources workflow, not inherited from the release.
 	UpdateSources string `json:"update-sources" yaml:"update-sources"`
+	// UpdateSchedule and AutoMergeSchedule are the cron schedules of the update-sources and
+	// auto-merge-upstream workflows.
+	UpdateSchedule    string `json:"update-schedule" yaml:"update-schedule"`
+	AutoMergeSchedule string `json:"auto-merge-schedule" yaml:"auto-merge-schedule"`
+	// MergeMethod is the method merging the update pull requests: merge, rebase or squash.
+	MergeMethod string `json:"merge-method" yaml:"merge-method"`
+	// RequiredLabels and RequiredChecks are the labels and the successful checks of the update pull requests
+	// before they are merged.
+	RequiredLabels []string `json:"required-labels" yaml:"required-labels"`
+	RequiredChecks []string `json:"required-checks" yaml:"required-checks"`
+	// RobotSecret is the secret of the token merging the pull requests, TokenSecret the one of the token
+	// creating them.
+	RobotSecret string `json:"robot-secret" yaml:"robot-secret"`
+	TokenSecret string `json:"token-secret" yaml:"token-secret"`
 }
 
 type Patch struct {
@@ -63,10 +192,17 @@ type Patch struct {
 	Script string
 }
 type Release struct {
-	Version      string
+	// Version is the version of the release, named after its release file.
+	Version      ReleaseVersion
 	PatchVersion string `json:"patch-version" yaml:"patch-version"`
 	ImagePrefix  string `json:"image-prefix" yaml:"image-prefix"`
 	ImageSuffix  string `json:"image-suffix" yaml:"image-suffix"`
+	// PipelineRevision pins the revision (branch, tag or commit) of the release pipeline of the version.
+	PipelineRevision string `json:"pipeline-revision" yaml:"pipeline-revision"`
+	// ReleaseImage is the image of cmd/release run by the release pipeline of the version, pinned by digest.
+	ReleaseImage string `json:"release-image" yaml:"release-image"`
+	// GitHub are the defaults of the GitHub workflows of the repositories of the version.
+	GitHub GitHub `json:"github" yaml:"github"`
 }
 
 type ApplicationConfig struct {
@@ -74,6 +210,32 @@ type ApplicationConfig struct {
 	Name            string
 	Org             string
 	ReleaseToGitHub bool `yaml:"release-to-github"`
+	// Tests are the IntegrationTestScenarios, an enterprise-contract scenario if empty.
+	Tests []TestScenario
+	// Releases are the release targets, a registry release if empty (and a GitHub release with release-to-github).
+	Releases []ReleaseTarget
+	// Boussole is the boussole configuration of the repositories of the application not configuring it.
+	Boussole *Boussole
+}
+
+// ReleaseTarget is a ReleasePlan of an application, with its own ServiceAccount and RoleBinding.
+type ReleaseTarget struct {
+	// Name is appended to the names of the ReleasePlan, ServiceAccount and RoleBinding, empty for the default target.
+	Name string
+	// GitHub creates the GitHub release of the released sources.
+	GitHub bool `json:"github" yaml:"github"`
+	// AutoRelease releases every Snapshot passing the tests, true if not set.
+	AutoRelease *bool `json:"auto-release" yaml:"auto-release"`
+	// Registry is the registry the images are released to, the release pipeline default if empty.
+	Registry string
+	// Secret is the pull secret of the release ServiceAccount, the tenant release secret if empty.
+	Secret string
+	// PipelineRevision is the revision of the release pipeline, the one of the release version if empty.
+	PipelineRevision string `json:"pipeline-revision" yaml:"pipeline-revision"`
+	// ReleaseImage is the image of cmd/release run by the release pipeline, the one of the release version if empty.
+	ReleaseImage string `json:"release-image" yaml:"release-image"`
+	// Params are additional parameters of the release pipeline, e.g. build_command.
+	Params []Param
 }
 
 type ReleaseConfig struct {
@@ -85,5 +247,5 @@ const (
 	konfluxDir          = ".konflux"
 	gitHubDir           = ".github"
 	tektonDir           = ".tekton"
-	autoGeneratedHeader = "# Generated for Konflux Application {{.Name}} by openshift-pipelines/hack. DO NOT EDIT"
+	autoGeneratedHeader = "# Generated for Konflux Application {{.ApplicationName}} by openshift-pipelines/hack. DO NOT EDIT"
 )
@@ -2,6 +2,7 @@ package konflux
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -64,11 +65,27 @@ func generateTektonConfig(repo Repository, targetDir string) error {
 	}
 
 	for _, c := range repo.Components {
-		v := c.Version
-		if err := generateFileFromTemplate("component-pull-request.yaml", c, filepath.Join(target, fmt.Sprintf("%s-%s-%s-pull-request.yaml", hyphenize(basename(repo.Name)), hyphenize(v.Version), c.Name)), repo.Application); err != nil {
+		v, err := newComponentView(c)
+		if err != nil {
 			return err
 		}
-		if err := generateFileFromTemplate("component-push.yaml", c, filepath.Join(target, fmt.Sprintf("%s-%s-%s-push.yaml", hyphenize(basename(repo.Name)), hyphenize(v.Version), c.Name)), repo.Application); err != nil {
+		if err := generateFileFromTemplate("component-pull-request.yaml", v, filepath.Join(targetDir, v.PullRequest.File)); err != nil {
+			return err
+		}
+		if err := generateFileFromTemplate("component-push.yaml", v, filepath.Join(targetDir, v.Push.File)); err != nil {
+			return err
+		}
+	}
+	if repo.Boussole != nil && repo.Boussole.Enabled {
+		if repo.boussoleOwner != "" {
+			log.Printf("Boussole of %s is generated by application %s\n", repo.Name, repo.boussoleOwner)
+			return nil
+		}
+		v, err := newBoussoleView(repo)
+		if err != nil {
+			return err
+		}
+		if err := generateFileFromTemplate("boussole.yaml", v, filepath.Join(target, "boussole.yaml")); err != nil {
 			return err
 		}
 	}
@@ -83,12 +100,13 @@ func generateGitHubConfig(repo Repository, targetDir string) error {
 		return err
 	}
 
+	v := newRepositoryView(repo)
 	filename := fmt.Sprintf("auto-merge-upstream-%s.yaml", repo.Name)
-	if err := generateFileFromTemplate("auto-merge-upstream.yaml", repo, filepath.Join(target, "workflows", filename), repo.Application); err != nil {
+	if err := generateFileFromTemplate("auto-merge-upstream.yaml", v, filepath.Join(target, "workflows", filename)); err != nil {
 		return err
 	}
 	filename = fmt.Sprintf("update-sources-%s.yaml", repo.Name)
-	if err := generateFileFromTemplate("update-sources.yaml", repo, filepath.Join(target, "workflows", filename), repo.Application); err != nil {
+	if err := generateFileFromTemplate("update-sources.yaml", v, filepath.Join(target, "workflows", filename)); err != nil {
 		return err
 	}
 	_, err := run(context.Background(), ".github", "cp", "renovate.json", target)
@@ -100,60 +118,125 @@ func generateGitHubConfig(repo Repository, targetDir string) error {
 }
 
 func generateKonfluxConfig(application Application) error {
-	targetDir := filepath.Join(konfluxDir, hyphenize(application.Release.Version), application.Name)
+	targetDir := filepath.Join(konfluxDir, application.Release.Version.Hyphenized(), application.Name)
 
-	log.Printf("Delete Konflux dir in %s\n", targetDir)
-	if err := os.RemoveAll(targetDir); err != nil {
+	// The configuration is generated in a staging directory swapped with targetDir once complete,
+	// so that a failed run leaves the previous configuration untouched.
+	stagingDir := filepath.Join(filepath.Dir(targetDir), "."+application.Name+".staging")
+	if err := os.RemoveAll(stagingDir); err != nil {
 		return err
 	}
+	defer os.RemoveAll(stagingDir)
 
-	if err := generateKonfluxApplication(application, targetDir); err != nil {
+	if err := generateKonfluxApplication(application, stagingDir); err != nil {
 		return err
 	}
 
-	if err := generateKonfluxComponents(application, targetDir); err != nil {
+	if err := generateKonfluxComponents(application, stagingDir); err != nil {
 		return err
 	}
 
-	return nil
+	log.Printf("Replace Konflux dir %s\n", targetDir)
+	return swapDir(stagingDir, targetDir)
 }
 
-func generateKonfluxApplication(application Application, targetDir string) error {
-	if err := generateFileFromTemplate("application.yaml", application, filepath.Join(targetDir, "application.yaml"), application); err != nil {
+// swapDir replaces targetDir by stagingDir, keeping the previous targetDir until the rename succeeded.
+func swapDir(stagingDir, targetDir string) error {
+	previousDir := stagingDir + ".previous"
+	if err := os.RemoveAll(previousDir); err != nil {
+		return err
+	}
+	if err := os.Rename(targetDir, previousDir); err != nil && !errors.Is(err, os.ErrNotExist) {
 		return err
 	}
-	if err := generateFileFromTemplate("tests.yaml", application, filepath.Join(targetDir, "tests.yaml"), application); err != nil {
+	if err := os.Rename(stagingDir, targetDir); err != nil {
+		// Restore the previous configuration
+		if rerr := os.Rename(previousDir, targetDir); rerr != nil && !errors.Is(rerr, os.ErrNotExist) {
+			return fmt.Errorf("%w (and failed to restore %s: %v)", err, targetDir, rerr)
+		}
 		return err
 	}
-	if err := generateFileFromTemplate("service-account.yaml", application, filepath.Join(targetDir, "service-account.yaml"), application); err != nil {
+	return os.RemoveAll(previousDir)
+}
+
+func generateKonfluxApplication(application Application, targetDir string) error {
+	v := newApplicationView(application)
+	if err := generateFileFromTemplate("application.yaml", v, filepath.Join(targetDir, "application.yaml")); err != nil {
 		return err
 	}
-	if err := generateFileFromTemplate("role.yaml", application, filepath.Join(targetDir, "role.yaml"), application); err != nil {
+	if err := generateTestScenarios(application, targetDir); err != nil {
 		return err
 	}
-	if application.ReleaseToGitHub {
-		tempApplication := application
-		tempApplication.AutoRelease = false
-		if err := generateFileFromTemplate("release-plan.yaml", tempApplication, filepath.Join(targetDir, "release-plan_github.yaml"), tempApplication); err != nil {
+	return generateReleasePlans(application, targetDir)
+}
+
+// generateTestScenarios renders the IntegrationTestScenarios of the application: the enterprise contract
+// scenario in tests.yaml and the others in tests-<name>.yaml.
+func generateTestScenarios(application Application, targetDir string) error {
+	files := map[string]string{}
+	for _, scenario := range application.Tests {
+		_, file := testScenarioFile(scenario)
+		if other, ok := files[file]; ok {
+			return fmt.Errorf("test scenarios %q and %q of application %s are both rendered in %s", other, scenario.Name, application.Name, file)
+		}
+		files[file] = scenario.Name
+	}
+	for _, scenario := range application.Tests {
+		template, file := testScenarioFile(scenario)
+		if err := generateFileFromTemplate(template, newTestScenarioView(application, scenario), filepath.Join(targetDir, file)); err != nil {
 			return err
 		}
 	}
-	application.ReleaseToGitHub = false
-	if err := generateFileFromTemplate("release-plan.yaml", application, filepath.Join(targetDir, "release-plan.yaml"), application); err != nil {
-		return err
+	return nil
+}
+
+// testScenarioFile returns the template and the file of a scenario, both chosen from its type.
+func testScenarioFile(scenario TestScenario) (string, string) {
+	if scenario.Type == TestScenarioEnterpriseContract {
+		return "tests.yaml", "tests.yaml"
 	}
+	return "release-tests.yaml", fmt.Sprintf("tests-%s.yaml", hyphenize(scenario.Name))
+}
 
+// GenerateReleasePlans regenerates the ReleasePlans (with their ServiceAccounts and RoleBindings) of an
+// application in its existing Konflux configuration.
+func GenerateReleasePlans(application Application) error {
+	targetDir := filepath.Join(konfluxDir, application.Release.Version.Hyphenized(), application.Name)
+	log.Printf("Generate %s release plans in %s\n", application.Name, targetDir)
+	return generateReleasePlans(application, targetDir)
+}
+
+// generateReleasePlans renders every release target of the application, in release-plan.yaml,
+// service-account.yaml and role.yaml for the default target and with a _<name> suffix for the others.
+func generateReleasePlans(application Application, targetDir string) error {
+	for _, target := range application.Releases {
+		v := newReleasePlanView(application, target)
+		suffix := ""
+		if target.Name != "" {
+			suffix = "_" + hyphenize(target.Name)
+		}
+		for _, template := range []string{"service-account.yaml", "role.yaml", "release-plan.yaml"} {
+			file := strings.TrimSuffix(template, ".yaml") + suffix + ".yaml"
+			if err := generateFileFromTemplate(template, v, filepath.Join(targetDir, file)); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
 func generateKonfluxComponents(application Application, targetDir string) error {
 	log.Printf("Generate %s konflux configuration in %s\n", application.Name, targetDir)
 	for _, c := range application.Components {
+		v, err := newComponentView(c)
+		if err != nil {
+			return err
+		}
 		componentDir := filepath.Join(targetDir, c.Repository.Name)
-		if err := generateFileFromTemplate("component.yaml", c, filepath.Join(componentDir, fmt.Sprintf("component-%s-%s.yaml", c.Name, application.Release.Version)), application); err != nil {
+		if err := generateFileFromTemplate("component.yaml", v, filepath.Join(componentDir, fmt.Sprintf("component-%s-%s.yaml", c.Name, application.Release.Version))); err != nil {
 			return err
 		}
-		if err := generateFileFromTemplate("image.yaml", c, filepath.Join(componentDir, fmt.Sprintf("image-%s-%s.yaml", c.Name, application.Release.Version)), application); err != nil {
+		if err := generateFileFromTemplate("image.yaml", v, filepath.Join(componentDir, fmt.Sprintf("image-%s-%s.yaml", c.Name, application.Release.Version))); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,72 @@
+package konflux
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestGenerateTestScenarios(t *testing.T) {
+	app := testApplication(t)
+	app.Tests = []TestScenario{{
+		Name:     "ec-strict",
+		Type:     TestScenarioEnterpriseContract,
+		Policy:   "tekton-ecosystem-tenant/strict",
+		Pipeline: GitResolver{URL: "https://github.com/konflux-ci/build-definitions", Revision: "main", PathInRepo: "pipelines/enterprise-contract.yaml"},
+	}, {
+		Name:     "e2e",
+		Type:     TestScenarioPipeline,
+		Pipeline: GitResolver{URL: "https://github.com/org/tests", Revision: "main", PathInRepo: "pipelines/e2e.yaml"},
+		Contexts: []TestContext{{Name: "push", Description: "push Snapshots"}},
+	}}
+
+	dir := t.TempDir()
+	if err := generateTestScenarios(*app, dir); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := []string{}
+	for _, e := range entries {
+		files = append(files, e.Name())
+	}
+	sort.Strings(files)
+	if strings.Join(files, ",") != "tests-e2e.yaml,tests.yaml" {
+		t.Fatalf("unexpected files %v", files)
+	}
+	wants := map[string][]string{
+		// The custom-named enterprise contract scenario is rendered with its template in tests.yaml
+		"tests.yaml":     {"name: pipelines-1-15-ec-strict", "name: POLICY_CONFIGURATION\n      value: tekton-ecosystem-tenant/strict", "value: pipelines/enterprise-contract.yaml"},
+		"tests-e2e.yaml": {"name: pipelines-1-15-e2e", "contexts:\n    - description: push Snapshots\n      name: push", "value: pipelines/e2e.yaml"},
+	}
+	for file, want := range wants {
+		content, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, w := range want {
+			if !strings.Contains(string(content), w) {
+				t.Errorf("%s doesn't contain %q:\n%s", file, w, content)
+			}
+		}
+	}
+	content, _ := os.ReadFile(filepath.Join(dir, "tests-e2e.yaml"))
+	if strings.Contains(string(content), "POLICY_CONFIGURATION") {
+		t.Errorf("tests-e2e.yaml is rendered with the enterprise contract template:\n%s", content)
+	}
+
+	// Two enterprise contract scenarios are rendered in the same file, nothing is written
+	app.Tests = append(app.Tests, TestScenario{Name: TestScenarioEnterpriseContract, Type: TestScenarioEnterpriseContract})
+	dir = t.TempDir()
+	err = generateTestScenarios(*app, dir)
+	if err == nil || !strings.Contains(err.Error(), `test scenarios "ec-strict" and "enterprise-contract" of application pipelines are both rendered in tests.yaml`) {
+		t.Fatalf("expected a duplicate file error, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected no file to be written, got %v", entries)
+	}
+}
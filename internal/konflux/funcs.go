@@ -0,0 +1,190 @@
+package konflux
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// TemplateFunc is a function available in the templates and in the strings evaluated with Eval.
+type TemplateFunc struct {
+	Name        string
+	Description string
+	// Example is a template using the function, which can be evaluated without data.
+	Example string
+	fn      interface{}
+}
+
+// templateFuncs are the functions of the templates, documented by the template-funcs command.
+var templateFuncs = []TemplateFunc{{
+	Name:        "hyphenize",
+	Description: "replaces the characters that are not lowercase letters or digits by hyphens",
+	Example:     `{{hyphenize "index-4.15"}}`,
+	fn:          hyphenize,
+}, {
+	Name:        "basename",
+	Description: "returns the last element of a path",
+	Example:     `{{basename "openshift-pipelines/tektoncd-cli"}}`,
+	fn:          basename,
+}, {
+	Name:        "indent",
+	Description: "indents every line of a string by the given number of spaces",
+	Example:     `{{indent 2 "a\nb"}}`,
+	fn:          indent,
+}, {
+	Name:        "contains",
+	Description: "reports whether the string contains the substring",
+	Example:     `{{contains "openshift-pipelines-index" "index"}}`,
+	fn:          strings.Contains,
+}, {
+	Name:        "eval",
+	Description: "evaluates a string as a template with the given data",
+	Example:     `{{eval "{{.}}-bundle" "tektoncd-operator"}}`,
+	fn:          Eval,
+}, {
+	Name:        "default",
+	Description: "returns the value, or the default if the value is empty",
+	Example:     `{{"" | default "main"}}`,
+	fn:          defaultValue,
+}, {
+	Name:        "lower",
+	Description: "converts a string to lowercase",
+	Example:     `{{lower "Tekton"}}`,
+	fn:          strings.ToLower,
+}, {
+	Name:        "trimPrefix",
+	Description: "removes the prefix of a string, if present",
+	Example:     `{{"release-v1.22.x" | trimPrefix "release-v"}}`,
+	fn:          trimPrefix,
+}, {
+	Name:        "hasPrefix",
+	Description: "reports whether the string starts with the prefix",
+	Example:     `{{"release-v1.22.x" | hasPrefix "release-v"}}`,
+	fn:          hasPrefix,
+}, {
+	Name:        "toJson",
+	Description: "encodes a value as JSON",
+	Example:     `{{toJson (dict "type" "rpm" "path" ".konflux/rpms")}}`,
+	fn:          toJSON,
+}, {
+	Name:        "toYaml",
+	Description: "encodes a value as YAML, without the trailing newline",
+	Example:     `{{toYaml (list "linux/x86_64" "linux/arm64")}}`,
+	fn:          toYAML,
+}, {
+	Name:        "join",
+	Description: "joins the elements of a list with the separator",
+	Example:     `{{list "a" "b" "c" | join ","}}`,
+	fn:          joinList,
+}, {
+	Name:        "semverMajorMinor",
+	Description: "returns the major.minor of a version, or the version itself if it's not a semantic version",
+	Example:     `{{semverMajorMinor "v1.22.3"}}`,
+	fn:          semverMajorMinor,
+}, {
+	Name:        "quote",
+	Description: "wraps a value in double quotes, escaping it",
+	Example:     `{{quote "a \"b\""}}`,
+	fn:          quote,
+}, {
+	Name:        "list",
+	Description: "returns a list of the arguments",
+	Example:     `{{list "a" "b"}}`,
+	fn:          list,
+}, {
+	Name:        "dict",
+	Description: "returns a map of the key and value pairs",
+	Example:     `{{(dict "name" "bundle").name}}`,
+	fn:          dict,
+}}
+
+// TemplateFuncs returns the functions available in the templates.
+func TemplateFuncs() []TemplateFunc {
+	return append([]TemplateFunc{}, templateFuncs...)
+}
+
+func defaultValue(d interface{}, value interface{}) interface{} {
+	if value == nil {
+		return d
+	}
+	if v := reflect.ValueOf(value); v.IsZero() || ((v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0) {
+		return d
+	}
+	return value
+}
+
+func trimPrefix(prefix, s string) string {
+	return strings.TrimPrefix(s, prefix)
+}
+
+func hasPrefix(prefix, s string) bool {
+	return strings.HasPrefix(s, prefix)
+}
+
+func toJSON(value interface{}) (string, error) {
+	out, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func toYAML(value interface{}) (string, error) {
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func joinList(sep string, values interface{}) (string, error) {
+	switch v := values.(type) {
+	case []string:
+		return strings.Join(v, sep), nil
+	case []interface{}:
+		s := make([]string, 0, len(v))
+		for _, e := range v {
+			s = append(s, fmt.Sprint(e))
+		}
+		return strings.Join(s, sep), nil
+	default:
+		return "", fmt.Errorf("join: expected a list, got %T", values)
+	}
+}
+
+var majorMinorPattern = regexp.MustCompile(`^v?([0-9]+\.[0-9]+)(\.[0-9]+)?([-+].*)?$`)
+
+func semverMajorMinor(version string) string {
+	if m := majorMinorPattern.FindStringSubmatch(version); m != nil {
+		return m[1]
+	}
+	return version
+}
+
+func quote(value interface{}) string {
+	return strconv.Quote(fmt.Sprint(value))
+}
+
+func list(values ...interface{}) []interface{} {
+	return values
+}
+
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict: expected key and value pairs, got %d arguments", len(pairs))
+	}
+	d := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: expected a string key, got %T", pairs[i])
+		}
+		d[key] = pairs[i+1]
+	}
+	return d, nil
+}
@@ -0,0 +1,108 @@
+package konflux
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	tests := []struct {
+		fn   string
+		tmpl string
+		data interface{}
+		want string
+		// err is a substring of the expected error
+		err string
+	}{
+		{fn: "hyphenize", tmpl: `{{hyphenize "index-4.15"}}`, want: "index-4-15"},
+		{fn: "hyphenize", tmpl: `{{hyphenize "tektoncd_cli 1.15"}}`, want: "tektoncd-cli-1-15"},
+		{fn: "basename", tmpl: `{{basename "openshift-pipelines/tektoncd-cli"}}`, want: "tektoncd-cli"},
+		{fn: "basename", tmpl: `{{basename "tektoncd-cli"}}`, want: "tektoncd-cli"},
+		{fn: "indent", tmpl: `{{indent 2 "a\nb"}}`, want: "  a\n  b"},
+		{fn: "indent", tmpl: `{{indent 0 "a"}}`, want: "a"},
+		{fn: "contains", tmpl: `{{contains "openshift-pipelines-index" "index"}}`, want: "true"},
+		{fn: "contains", tmpl: `{{contains "openshift-pipelines" "index"}}`, want: "false"},
+		{fn: "eval", tmpl: `{{eval "{{.}}-bundle" "tektoncd-operator"}}`, want: "tektoncd-operator-bundle"},
+		{fn: "eval", tmpl: `{{eval "{{.Missing}}" .}}`, data: map[string]string{}, want: "<no value>"},
+		{fn: "eval", tmpl: `{{eval "{{" "x"}}`, err: "unclosed action"},
+		{fn: "default", tmpl: `{{"" | default "main"}}`, want: "main"},
+		{fn: "default", tmpl: `{{"next" | default "main"}}`, want: "next"},
+		{fn: "default", tmpl: `{{0 | default 1}}`, want: "1"},
+		{fn: "default", tmpl: `{{false | default true}}`, want: "true"},
+		{fn: "default", tmpl: `{{default "main" nil}}`, want: "main"},
+		{fn: "default", tmpl: `{{.Empty | default "none"}}`, data: map[string]interface{}{"Empty": []string{}}, want: "none"},
+		{fn: "default", tmpl: `{{.Empty | default "none"}}`, data: map[string]interface{}{"Empty": map[string]string{}}, want: "none"},
+		{fn: "default", tmpl: `{{.Missing | default "none"}}`, data: map[string]interface{}{}, want: "none"},
+		{fn: "default", tmpl: `{{.List | default "none" | join ","}}`, data: map[string]interface{}{"List": []string{"a"}}, want: "a"},
+		{fn: "lower", tmpl: `{{lower "Tekton"}}`, want: "tekton"},
+		{fn: "trimPrefix", tmpl: `{{"release-v1.22.x" | trimPrefix "release-v"}}`, want: "1.22.x"},
+		{fn: "trimPrefix", tmpl: `{{"main" | trimPrefix "release-v"}}`, want: "main"},
+		{fn: "hasPrefix", tmpl: `{{"release-v1.22.x" | hasPrefix "release-v"}}`, want: "true"},
+		{fn: "hasPrefix", tmpl: `{{"main" | hasPrefix "release-v"}}`, want: "false"},
+		{fn: "toJson", tmpl: `{{toJson (dict "type" "rpm" "path" ".konflux/rpms")}}`, want: `{"path":".konflux/rpms","type":"rpm"}`},
+		{fn: "toJson", tmpl: `{{toJson (list)}}`, want: `[]`},
+		{fn: "toYaml", tmpl: `{{toYaml (list "linux/x86_64" "linux/arm64")}}`, want: "- linux/x86_64\n- linux/arm64"},
+		{fn: "toYaml", tmpl: `{{toYaml (dict "a" 1)}}`, want: "a: 1"},
+		{fn: "join", tmpl: `{{list "a" "b" "c" | join ","}}`, want: "a,b,c"},
+		{fn: "join", tmpl: `{{list "a" 1 true | join " "}}`, want: "a 1 true"},
+		{fn: "join", tmpl: `{{list | join ","}}`, want: ""},
+		{fn: "join", tmpl: `{{.List | join "/"}}`, data: map[string]interface{}{"List": []string{"x", "y"}}, want: "x/y"},
+		{fn: "join", tmpl: `{{"a" | join ","}}`, err: "join: expected a list, got string"},
+		{fn: "semverMajorMinor", tmpl: `{{semverMajorMinor "v1.22.3"}}`, want: "1.22"},
+		{fn: "semverMajorMinor", tmpl: `{{semverMajorMinor "1.22"}}`, want: "1.22"},
+		{fn: "semverMajorMinor", tmpl: `{{semverMajorMinor "1.22.0-rc.1"}}`, want: "1.22"},
+		{fn: "semverMajorMinor", tmpl: `{{semverMajorMinor "main"}}`, want: "main"},
+		{fn: "semverMajorMinor", tmpl: `{{semverMajorMinor "1"}}`, want: "1"},
+		{fn: "semverMajorMinor", tmpl: `{{semverMajorMinor "1.22.x"}}`, want: "1.22.x"},
+		{fn: "quote", tmpl: `{{quote "a \"b\""}}`, want: `"a \"b\""`},
+		{fn: "quote", tmpl: `{{quote "a: b # c"}}`, want: `"a: b # c"`},
+		{fn: "quote", tmpl: `{{quote "a\nb\\c"}}`, want: `"a\nb\\c"`},
+		{fn: "quote", tmpl: `{{quote 1}}`, want: `"1"`},
+		{fn: "list", tmpl: `{{list "a" "b"}}`, want: "[a b]"},
+		{fn: "list", tmpl: `{{len (list)}}`, want: "0"},
+		{fn: "dict", tmpl: `{{(dict "name" "bundle").name}}`, want: "bundle"},
+		{fn: "dict", tmpl: `{{len (dict)}}`, want: "0"},
+		{fn: "dict", tmpl: `{{dict "name"}}`, err: "dict: expected key and value pairs, got 1 arguments"},
+		{fn: "dict", tmpl: `{{dict "a" 1 "b"}}`, err: "dict: expected key and value pairs, got 3 arguments"},
+		{fn: "dict", tmpl: `{{dict 1 "a"}}`, err: "dict: expected a string key, got int"},
+	}
+	tested := map[string]bool{}
+	for _, tt := range tests {
+		tested[tt.fn] = true
+		t.Run(tt.fn, func(t *testing.T) {
+			got, err := Eval(tt.tmpl, tt.data)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("%s: expected error %q, got %q, %v", tt.tmpl, tt.err, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", tt.tmpl, err)
+			}
+			if got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.tmpl, got, tt.want)
+			}
+		})
+	}
+	for _, f := range templateFuncs {
+		if !tested[f.Name] {
+			t.Errorf("function %s is not tested", f.Name)
+		}
+	}
+}
+
+func TestTemplateFuncExamples(t *testing.T) {
+	for _, f := range TemplateFuncs() {
+		if f.Example == "" {
+			t.Errorf("function %s has no example", f.Name)
+			continue
+		}
+		if !strings.Contains(f.Example, f.Name) {
+			t.Errorf("example %s of function %s doesn't use it", f.Example, f.Name)
+		}
+		if _, err := Eval(f.Example, nil); err != nil {
+			t.Errorf("example %s of function %s: %v", f.Example, f.Name, err)
+		}
+	}
+}
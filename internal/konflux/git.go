@@ -16,7 +16,7 @@ const baseBranchPrefix = "hack/"
 func cloneAndCheckout(ctx context.Context, repo Repository, targetDir string) (string, error) {
 	branch := repo.Branch.Name
 	branchPrefix := baseBranchPrefix + repo.Application.Name + "/"
-	dir := filepath.Join(targetDir, repo.Application.Release.Version, repo.Name)
+	dir := filepath.Join(targetDir, repo.Application.Release.Version.String(), repo.Name)
 	exists, err := exists(filepath.Join(dir, ".git"))
 
 	if err != nil {
@@ -74,8 +74,12 @@ func commitAndPullRequest(ctx context.Context, repo Repository, dir string) erro
 		log.Printf("[%s] No changes, skipping commit and PR", dir)
 		return nil
 	}
-	if out, err := run(ctx, dir, "bash", "-c", "git config user.name openshift-pipelines-bot; git config user.email pipelines-extcomm@redhat.com"); err != nil {
-		return fmt.Errorf("failed to set some git configurations: %s, %s", err, out)
+	tenant := repo.Application.Tenant
+	if out, err := run(ctx, dir, "git", "config", "user.name", tenant.BotName); err != nil {
+		return fmt.Errorf("failed to set git user.name: %s, %s", err, out)
+	}
+	if out, err := run(ctx, dir, "git", "config", "user.email", tenant.BotEmail); err != nil {
+		return fmt.Errorf("failed to set git user.email: %s, %s", err, out)
 	}
 	if out, err := run(ctx, dir, "git", "add", "."); err != nil {
 		return fmt.Errorf("failed to add: %s, %s", err, out)
@@ -0,0 +1,85 @@
+package konflux
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+)
+
+var mergeMethods = []string{"merge", "rebase", "squash"}
+
+// secretNamePattern matches the names of the GitHub Actions secrets.
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// WithDefaults returns the configuration with its empty fields set from d, except UpdateSources.
+func (g GitHub) WithDefaults(d GitHub) GitHub {
+	if g.UpdateSchedule == "" {
+		g.UpdateSchedule = d.UpdateSchedule
+	}
+	if g.AutoMergeSchedule == "" {
+		g.AutoMergeSchedule = d.AutoMergeSchedule
+	}
+	if g.MergeMethod == "" {
+		g.MergeMethod = d.MergeMethod
+	}
+	if g.RequiredLabels == nil {
+		g.RequiredLabels = d.RequiredLabels
+	}
+	if g.RequiredChecks == nil {
+		g.RequiredChecks = d.RequiredChecks
+	}
+	if g.RobotSecret == "" {
+		g.RobotSecret = d.RobotSecret
+	}
+	if g.TokenSecret == "" {
+		g.TokenSecret = d.TokenSecret
+	}
+	return g
+}
+
+// Validate checks the schedules, the merge method and the secret names.
+func (g GitHub) Validate() error {
+	errs := []string{}
+	for name, schedule := range map[string]string{"update-schedule": g.UpdateSchedule, "auto-merge-schedule": g.AutoMergeSchedule} {
+		if err := validateCron(schedule); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if !contains(mergeMethods, g.MergeMethod) {
+		errs = append(errs, fmt.Sprintf("merge-method %q: must be one of %s", g.MergeMethod, strings.Join(mergeMethods, ", ")))
+	}
+	for name, secret := range map[string]string{"robot-secret": g.RobotSecret, "token-secret": g.TokenSecret} {
+		if !secretNamePattern.MatchString(secret) {
+			errs = append(errs, fmt.Sprintf("%s %q: not a valid secret name", name, secret))
+		}
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("invalid github configuration: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// validateCron checks a POSIX cron expression, as supported by the GitHub Actions schedules: five fields,
+// without the descriptors (@daily) and the time zones the cron parser also accepts.
+func validateCron(expression string) error {
+	if strings.HasPrefix(expression, "@") || strings.Contains(expression, "TZ=") {
+		return fmt.Errorf("cron expression %q: descriptors and time zones are not supported by GitHub Actions", expression)
+	}
+	if _, err := cron.ParseStandard(expression); err != nil {
+		return fmt.Errorf("cron expression %q: %w", expression, err)
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
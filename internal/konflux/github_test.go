@@ -0,0 +1,106 @@
+package konflux
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testGitHubDefaults are the defaults of the loader.
+var testGitHubDefaults = GitHub{
+	UpdateSchedule:    "0 1 * * *",
+	AutoMergeSchedule: "*/30 * * * *",
+	MergeMethod:       "rebase",
+	RobotSecret:       "OPENSHIFT_PIPELINES_ROBOT",
+	TokenSecret:       "GITHUB_TOKEN",
+}
+
+func TestGitHubWithDefaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		config GitHub
+		want   GitHub
+	}{{
+		name: "empty configuration, without the update sources",
+		want: testGitHubDefaults,
+	}, {
+		name:   "configured fields are kept",
+		config: GitHub{UpdateSchedule: "0 2 * * MON-FRI", MergeMethod: "squash", RequiredLabels: []string{"lgtm"}},
+		want: GitHub{
+			UpdateSchedule:    "0 2 * * MON-FRI",
+			AutoMergeSchedule: "*/30 * * * *",
+			MergeMethod:       "squash",
+			RequiredLabels:    []string{"lgtm"},
+			RobotSecret:       "OPENSHIFT_PIPELINES_ROBOT",
+			TokenSecret:       "GITHUB_TOKEN",
+		},
+	}, {
+		name:   "empty lists are kept",
+		config: GitHub{RequiredChecks: []string{}},
+		want: GitHub{
+			UpdateSchedule:    "0 1 * * *",
+			AutoMergeSchedule: "*/30 * * * *",
+			MergeMethod:       "rebase",
+			RequiredChecks:    []string{},
+			RobotSecret:       "OPENSHIFT_PIPELINES_ROBOT",
+			TokenSecret:       "GITHUB_TOKEN",
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaults := testGitHubDefaults
+			defaults.UpdateSources = "make vendor"
+			if got := tt.config.WithDefaults(defaults); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WithDefaults() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHubValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config GitHub
+		err    string
+	}{{
+		name:   "defaults",
+		config: GitHub{}.WithDefaults(testGitHubDefaults),
+	}, {
+		name:   "valid schedules",
+		config: GitHub{UpdateSchedule: "15,45 2-4 1 JAN-JUN MON-FRI", AutoMergeSchedule: "0 */6 * * 0"}.WithDefaults(testGitHubDefaults),
+	}, {
+		name:   "too few fields",
+		config: GitHub{UpdateSchedule: "0 1 * *"}.WithDefaults(testGitHubDefaults),
+		err:    `update-schedule: cron expression "0 1 * *": expected exactly 5 fields, found 4`,
+	}, {
+		name:   "out of range value",
+		config: GitHub{AutoMergeSchedule: "0 25 * * *"}.WithDefaults(testGitHubDefaults),
+		err:    `auto-merge-schedule: cron expression "0 25 * * *": end of range (25) above maximum (23): 25`,
+	}, {
+		name:   "descriptor",
+		config: GitHub{UpdateSchedule: "@daily"}.WithDefaults(testGitHubDefaults),
+		err:    `update-schedule: cron expression "@daily": descriptors and time zones are not supported by GitHub Actions`,
+	}, {
+		name:   "empty schedule",
+		config: GitHub{MergeMethod: "rebase", RobotSecret: "ROBOT", TokenSecret: "TOKEN"},
+		err:    `auto-merge-schedule: cron expression "": empty spec string`,
+	}, {
+		name:   "merge method and secrets",
+		config: GitHub{MergeMethod: "fast-forward", RobotSecret: "robot-token", TokenSecret: "1TOKEN"}.WithDefaults(testGitHubDefaults),
+		err:    `invalid github configuration: merge-method "fast-forward": must be one of merge, rebase, squash, robot-secret "robot-token": not a valid secret name, token-secret "1TOKEN": not a valid secret name`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -3,12 +3,15 @@ package konflux
 import (
 	"bytes"
 	"embed"
-	"log"
+	"fmt"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"text/template"
 )
 
@@ -17,14 +20,37 @@ var templateFS embed.FS
 
 var nameFieldInvalidCharPattern = regexp.MustCompile("[^a-z0-9]")
 
-func Eval(tmpl string, data interface{}) (string, error) {
-	funcMap := template.FuncMap{
-		"hyphenize": hyphenize,
-		"basename":  basename,
-		"indent":    indent,
-		"contains":  strings.Contains,
+// funcMap is shared by the templates and the evaluated strings.
+var funcMap = template.FuncMap{}
+
+func init() {
+	// Registered at init time as eval uses funcMap
+	for _, f := range templateFuncs {
+		funcMap[f.Name] = f.fn
 	}
-	t, err := template.New("inner").Funcs(funcMap).Parse(tmpl)
+}
+
+// templates is the registry of the parsed templates, reused for every generated file.
+var templates = newTemplateRegistry()
+
+// templateRegistry parses the embedded templates once, and caches the templates extended with
+// an overlay directory or overrides, as well as the strings evaluated with Eval.
+type templateRegistry struct {
+	mu       sync.Mutex
+	embedded *template.Template
+	sets     map[string]*template.Template
+	evals    map[string]*template.Template
+}
+
+func newTemplateRegistry() *templateRegistry {
+	return &templateRegistry{
+		sets:  map[string]*template.Template{},
+		evals: map[string]*template.Template{},
+	}
+}
+
+func Eval(tmpl string, data interface{}) (string, error) {
+	t, err := templates.eval(tmpl)
 	if err != nil {
 		return "", err
 	}
@@ -35,45 +61,157 @@ func Eval(tmpl string, data interface{}) (string, error) {
 	}
 	return buf.String(), nil
 }
-func generateFileFromTemplate(templateFile string, data interface{}, filePath string, application Application) error {
-	funcMap := template.FuncMap{
-		"hyphenize": hyphenize,
-		"basename":  basename,
-		"indent":    indent,
-		"contains":  strings.Contains,
-		"eval":      Eval,
-	}
-	tmpl, err := template.New(templateFile).Funcs(funcMap).ParseFS(templateFS, "templates/*/*.yaml", "templates/*/*/*.yaml")
+
+func (r *templateRegistry) eval(tmpl string) (*template.Template, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t, ok := r.evals[tmpl]; ok {
+		return t, nil
+	}
+	t, err := template.New("inner").Funcs(funcMap).Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	r.evals[tmpl] = t
+	return t, nil
+}
+
+// lookup returns the templates extended with the overlay directory and the overrides, parsing them on first use.
+func (r *templateRegistry) lookup(templateDir string, overrides map[string]string) (*template.Template, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.embedded == nil {
+		embedded, err := template.New("embedded").Funcs(funcMap).ParseFS(templateFS, "templates/*/*.yaml", "templates/*/*/*.yaml")
+		if err != nil {
+			return nil, err
+		}
+		r.embedded = embedded
+	}
+	if templateDir == "" && len(overrides) == 0 {
+		return r.embedded, nil
+	}
+	// The overrides also apply to the templates included with {{template}}, so the set is cached for
+	// the whole overrides and not per overridden file.
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	key := templateDir
+	for _, name := range names {
+		key += "\x00" + name + "\x00" + overrides[name]
+	}
+	if t, ok := r.sets[key]; ok {
+		return t, nil
+	}
+	t, err := r.embedded.Clone()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	parentDir := filepath.Dir(filePath)
-	err = os.MkdirAll(parentDir, os.ModePerm)
+	if err := parseOverrides(t, templateDir, names, overrides); err != nil {
+		return nil, err
+	}
+	r.sets[key] = t
+	return t, nil
+}
+
+func generateFileFromTemplate(templateFile string, data view, filePath string) error {
+	source := data.source()
+	tmpl, err := templates.lookup(source.templateDir, source.overrides)
 	if err != nil {
-		log.Fatal("Error creating directory:", parentDir, err)
 		return err
 	}
-	file, err := os.Create(filePath)
+	// Add AutoGenerated Header
+	header, err := Eval(autoGeneratedHeader, source)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	var buf bytes.Buffer
+	buf.WriteString(header + "\n")
+	if err := tmpl.ExecuteTemplate(&buf, templateFile, data); err != nil {
+		return err
+	}
+	content := normalizeOutput(buf.Bytes())
+	if err := validateManifests(content); err != nil {
+		return fmt.Errorf("template %s for %s: %w", templateFile, describe(data), err)
+	}
+	return writeFileAtomic(filePath, content)
+}
+
+// normalizeOutput strips the trailing whitespaces of the lines and ends the content with a single newline,
+// so that the output doesn't depend on the whitespace control of the templates.
+func normalizeOutput(content []byte) []byte {
+	lines := strings.Split(strings.TrimRight(string(content), " \t\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
 
-	// Add AutoGenerated Header
-	header, err := Eval(autoGeneratedHeader, application)
+// writeFileAtomic writes the content to a temporary file next to filePath and renames it, so that
+// filePath is either left untouched or entirely written.
+func writeFileAtomic(filePath string, content []byte) error {
+	parentDir := filepath.Dir(filePath)
+	if err := os.MkdirAll(parentDir, os.ModePerm); err != nil {
+		return fmt.Errorf("error creating directory %s: %w", parentDir, err)
+	}
+	tmp, err := os.CreateTemp(parentDir, "."+filepath.Base(filePath)+".*")
 	if err != nil {
 		return err
 	}
-	if _, err := file.WriteString(header + "\n"); err != nil {
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
 		return err
 	}
+	return os.Rename(tmp.Name(), filePath)
+}
+
+// parseOverrides parses the templates of the overlay directory and then the overrides (in the order of
+// names) on top of the embedded templates. Templates are looked up by file name, so a file of the
+// overlay directory or an override replaces the embedded template with the same name.
+// A file only made of {{define}} actions keeps the template body and only replaces the given blocks.
+func parseOverrides(tmpl *template.Template, templateDir string, names []string, overrides map[string]string) error {
+	if templateDir != "" {
+		overlays, err := fs.Glob(os.DirFS(templateDir), "*/*.yaml")
+		if err != nil {
+			return err
+		}
+		nested, err := fs.Glob(os.DirFS(templateDir), "*/*/*.yaml")
+		if err != nil {
+			return err
+		}
+		for _, overlay := range append(overlays, nested...) {
+			if err := parseTemplateFile(tmpl, path.Base(overlay), filepath.Join(templateDir, overlay)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, name := range names {
+		if err := parseTemplateFile(tmpl, name, overrides[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	err = tmpl.Execute(file, data)
+func parseTemplateFile(tmpl *template.Template, name string, file string) error {
+	content, err := os.ReadFile(file)
 	if err != nil {
 		return err
 	}
+	if _, err := tmpl.New(name).Parse(string(content)); err != nil {
+		return fmt.Errorf("failed to parse template %s: %w", file, err)
+	}
 	return nil
 }
+
 func hyphenize(str string) string {
 	return nameFieldInvalidCharPattern.ReplaceAllString(str, "-")
 }
@@ -86,3 +224,23 @@ func indent(spaces int, v string) string {
 	pad := strings.Repeat(" ", spaces)
 	return pad + strings.Replace(v, "\n", "\n"+pad, -1)
 }
+
+// describe returns a human readable name of the data a template is executed with.
+func describe(data view) string {
+	switch d := data.(type) {
+	case ComponentView:
+		return fmt.Sprintf("component %s", d.Name)
+	case RepositoryView:
+		return fmt.Sprintf("repository %s", d.Name)
+	case ApplicationView:
+		return fmt.Sprintf("application %s", d.ApplicationName)
+	case TestScenarioView:
+		return fmt.Sprintf("test scenario %s", d.ScenarioName)
+	case ReleasePlanView:
+		return fmt.Sprintf("release plan %s", d.ReleasePlan)
+	case BoussoleView:
+		return fmt.Sprintf("boussole of repository %s", d.Repository)
+	default:
+		return fmt.Sprintf("%T", data)
+	}
+}
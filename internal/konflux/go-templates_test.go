@@ -0,0 +1,112 @@
+package konflux
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testApplication returns an application with a repository and its component, as set up by the loader.
+func testApplication(t testing.TB) *Application {
+	t.Helper()
+	version, err := ParseReleaseVersion("1.15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &Application{
+		Name:    "pipelines",
+		Release: &Release{Version: version, PatchVersion: "1.15.1"},
+		Tenant: Tenant{
+			Namespace: "tekton-ecosystem-tenant",
+			Registry:  "quay.io/redhat-user-workloads/tekton-ecosystem-tenant",
+			Policy:    "tekton-ecosystem-tenant",
+		},
+	}
+	repo := Repository{
+		Name:        "tektoncd-cli",
+		Upstream:    "tektoncd/cli",
+		Url:         "https://github.com/openshift-pipelines/tektoncd-cli",
+		Branch:      Branch{Name: "release-v1.15.x", UpstreamBranch: "release-v0.37.x"},
+		Application: app,
+	}
+	repo.Components = []Component{{
+		Name:        "tkn",
+		Dockerfile:  ".konflux/dockerfiles/tkn.Dockerfile",
+		Version:     *app.Release,
+		Repository:  &repo,
+		Application: app,
+		Build:       Build{Kind: BuildKindContainer, PipelineURL: "https://example.com/docker-build.yaml"},
+	}}
+	app.Repositories = []Repository{repo}
+	app.Components = repo.Components
+	return app
+}
+
+func TestTemplateOverrides(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"outer.yaml":   `outer: {{template "inner.yaml" .}}`,
+		"inner.yaml":   `{{.ApplicationName}}-inner`,
+		"inner-2.yaml": `{{.ApplicationName}}-other`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	registry := newTemplateRegistry()
+	execute := func(overrides map[string]string) string {
+		t.Helper()
+		tmpl, err := registry.lookup("", overrides)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := &strings.Builder{}
+		if err := tmpl.ExecuteTemplate(out, "outer.yaml", generated{ApplicationName: "app"}); err != nil {
+			t.Fatal(err)
+		}
+		return out.String()
+	}
+
+	// The included template is overridden too
+	overrides := map[string]string{"outer.yaml": filepath.Join(dir, "outer.yaml"), "inner.yaml": filepath.Join(dir, "inner.yaml")}
+	if got := execute(overrides); got != "outer: app-inner" {
+		t.Errorf("got %q", got)
+	}
+	// Another override of the included template is not served from the cache
+	other := map[string]string{"outer.yaml": filepath.Join(dir, "outer.yaml"), "inner.yaml": filepath.Join(dir, "inner-2.yaml")}
+	if got := execute(other); got != "outer: app-other" {
+		t.Errorf("got %q", got)
+	}
+	if got := execute(overrides); got != "outer: app-inner" {
+		t.Errorf("got %q", got)
+	}
+	if len(registry.sets) != 2 {
+		t.Errorf("expected 2 cached sets, got %d", len(registry.sets))
+	}
+}
+
+func BenchmarkGenerateFileFromTemplate(b *testing.B) {
+	app := testApplication(b)
+	view, err := newComponentView(app.Components[0])
+	if err != nil {
+		b.Fatal(err)
+	}
+	file := filepath.Join(b.TempDir(), "push.yaml")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := generateFileFromTemplate("component-push.yaml", view, file); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEval(b *testing.B) {
+	c := testApplication(b).Components[0]
+	for i := 0; i < b.N; i++ {
+		if _, err := Eval("tektoncd-operator-{{.Version.Version.Hyphenized}}-bundle", c); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package konflux
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	// DefaultReleaseRegistry is the registry the images are released to.
+	DefaultReleaseRegistry = "quay.io/openshift-pipeline"
+	// BuildImagePrefix is the prefix of the build images that is dropped from the released images.
+	BuildImagePrefix = "pipeline-"
+)
+
+// ComponentImages are the images of a component, from its build to its release.
+type ComponentImages struct {
+	// Build is the image repository the component is built to, in the tenant registry.
+	Build string
+	// Release is the image repository the component is released to.
+	Release string
+	// Tags are the tags of the released image.
+	Tags []string
+}
+
+// Images returns the images of the component, released to releaseRegistry with the tags of the patch version.
+// The digest of the release tag is only known once built, it's shown as <digest>.
+func (c Component) Images(releaseRegistry string) ComponentImages {
+	return ComponentImages{
+		Build:   c.BuildImage(),
+		Release: ReleaseRepository(c.BuildImage(), releaseRegistry),
+		Tags:    ReleaseTags(c.Version.PatchVersion, "<digest>"),
+	}
+}
+
+// ReleaseRepository returns the repository an image built by Konflux is released to: the image is
+// moved to releaseRegistry, without its pipeline- prefix.
+func ReleaseRepository(buildRepository string, releaseRegistry string) string {
+	return fmt.Sprintf("%s/%s", strings.TrimSuffix(releaseRegistry, "/"), strings.TrimPrefix(path.Base(buildRepository), BuildImagePrefix))
+}
+
+// ReleaseTags returns the tags of a released image: the version and the sha-<digest> tag.
+func ReleaseTags(version string, digest string) []string {
+	return []string{version, "sha-" + strings.TrimPrefix(digest, "sha256:")}
+}
+
+// BuildImage returns the image repository the component is built to, in the tenant registry.
+func (c Component) BuildImage() string {
+	return c.Application.Tenant.Registry + "/" + c.imageName()
+}
+
+// imageName is the name of the image built for the component in the tenant registry.
+func (c Component) imageName() string {
+	return c.ImagePrefix + c.Name + c.ImageSuffix
+}
@@ -0,0 +1,200 @@
+package konflux
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxNameLength is the maximum length of the names of the generated resources: Konflux uses the
+// Component and Application names in labels, whose values are limited to 63 characters.
+const maxNameLength = 63
+
+// DefaultPipelineRevision is the revision of the release pipeline when it's not pinned.
+const DefaultPipelineRevision = "main"
+
+// hashLength is the length of the hash suffix of the shortened names.
+const hashLength = 8
+
+// ResourceName returns the name of the Application resource.
+func (a Application) ResourceName() string {
+	return a.shortName(fmt.Sprintf("%s-%s", hyphenize(a.Name), a.Release.Version.Hyphenized()))
+}
+
+// ReleasePlanName returns the name of the ReleasePlan of a release target, with the target name infix.
+func (a Application) ReleasePlanName(target ReleaseTarget) string {
+	return a.shortName(fmt.Sprintf("%s-%s%s-rp", hyphenize(basename(a.Name)), a.Release.Version.Hyphenized(), target.suffix()))
+}
+
+// ServiceAccountName returns the name of the ServiceAccount used by the release pipeline of a release target.
+func (a Application) ServiceAccountName(target ReleaseTarget) string {
+	return a.shortName(fmt.Sprintf("release-registry-%s-%s%s", hyphenize(a.Name), a.Release.Version.Hyphenized(), target.suffix()))
+}
+
+// RoleBindingName returns the name of the RoleBinding of the release ServiceAccount of a release target.
+func (a Application) RoleBindingName(target ReleaseTarget) string {
+	return a.shortName(fmt.Sprintf("release-plan-rolebinding-%s-%s%s", hyphenize(a.Name), a.Release.Version.Hyphenized(), target.suffix()))
+}
+
+// PipelineRevision returns the revision of the release pipeline of a release target: the one of the target,
+// else the one pinned by the release version, else main.
+func (a Application) PipelineRevision(target ReleaseTarget) string {
+	switch {
+	case target.PipelineRevision != "":
+		return target.PipelineRevision
+	case a.Release.PipelineRevision != "":
+		return a.Release.PipelineRevision
+	default:
+		return DefaultPipelineRevision
+	}
+}
+
+// ReleaseImage returns the cmd/release image of the release pipeline of a release target: the one of the target,
+// else the one of the release version, empty if none is set.
+func (a Application) ReleaseImage(target ReleaseTarget) string {
+	if target.ReleaseImage != "" {
+		return target.ReleaseImage
+	}
+	return a.Release.ReleaseImage
+}
+
+// releaseImagePattern matches an image reference pinned by digest.
+var releaseImagePattern = regexp.MustCompile(`^[^@\s]+@sha256:[0-9a-f]{64}$`)
+
+// ValidateReleaseImage checks that the cmd/release image, when set, is pinned by digest, so that the
+// release pipeline only runs a reviewed build of the release command.
+func ValidateReleaseImage(image string) error {
+	if image != "" && !releaseImagePattern.MatchString(image) {
+		return fmt.Errorf("release-image %q: must be pinned by digest (<repository>:<version>@sha256:<digest>)", image)
+	}
+	return nil
+}
+
+// suffix is appended to the names of the resources of the release target, empty for the default target.
+func (t ReleaseTarget) suffix() string {
+	if t.Name == "" {
+		return ""
+	}
+	return "-" + hyphenize(t.Name)
+}
+
+// EnterpriseContractName returns the name of the default enterprise contract IntegrationTestScenario.
+func (a Application) EnterpriseContractName() string {
+	return a.TestScenarioName(TestScenarioEnterpriseContract)
+}
+
+// TestScenarioName returns the name of an IntegrationTestScenario of the application.
+func (a Application) TestScenarioName(scenario string) string {
+	return a.shortName(fmt.Sprintf("%s-%s-%s", hyphenize(a.Name), a.Release.Version.Hyphenized(), hyphenize(scenario)))
+}
+
+// shortName shortens the name when it's too long and the application opted in.
+func (a Application) shortName(name string) string {
+	if !a.ShortenNames {
+		return name
+	}
+	return shortenName(name, maxNameLength)
+}
+
+// ResourceName returns the name of the Component resource.
+func (c Component) ResourceName() string {
+	return c.Application.shortName(fmt.Sprintf("%s-%s-%s", hyphenize(basename(c.Repository.Name)), hyphenize(c.Name), c.Version.Version.Hyphenized()))
+}
+
+// ImageRepositoryName returns the name of the ImageRepository resource, shared by all the versions.
+func (c Component) ImageRepositoryName() string {
+	return c.Application.shortName(hyphenize(c.ImagePrefix) + hyphenize(c.Name) + hyphenize(c.ImageSuffix))
+}
+
+// PipelineRunName returns the name of the build PipelineRun for the event (pull-request or push).
+func (c Component) PipelineRunName(event string) string {
+	return c.Application.shortName(fmt.Sprintf("%s-%s-%s-on-%s", hyphenize(basename(c.Repository.Name)), c.Version.Version.Hyphenized(), c.Name, event))
+}
+
+// PipelineRunComponent returns the component label of the build PipelineRuns.
+func (c Component) PipelineRunComponent() string {
+	return c.Application.shortName(fmt.Sprintf("%s-%s-%s", hyphenize(basename(c.Repository.Name)), c.Version.Version.Hyphenized(), hyphenize(c.Name)))
+}
+
+// BuildServiceAccountName returns the name of the ServiceAccount created by Konflux for the component builds.
+func (c Component) BuildServiceAccountName() string {
+	return c.Application.shortName("build-pipeline-" + c.ResourceName())
+}
+
+// shortenName truncates the name to maxLength characters, replacing the end with a stable hash of the full name.
+func shortenName(name string, maxLength int) string {
+	if len(name) <= maxLength {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	prefix := strings.TrimRight(name[:maxLength-hashLength-1], "-.")
+	return prefix + "-" + hex.EncodeToString(sum[:])[:hashLength]
+}
+
+// resourceName is a generated resource, owned by a component or an application.
+type resourceName struct {
+	Kind  string
+	Name  string
+	Owner string
+}
+
+// resourceNames returns the names of all the resources generated for the application.
+func resourceNames(application Application) []resourceName {
+	owner := fmt.Sprintf("application %s (%s)", application.Name, application.Release.Version)
+	names := []resourceName{
+		{Kind: "Application", Name: application.ResourceName(), Owner: owner},
+	}
+	for _, scenario := range application.Tests {
+		names = append(names, resourceName{Kind: "IntegrationTestScenario", Name: application.TestScenarioName(scenario.Name), Owner: owner})
+	}
+	for _, target := range application.Releases {
+		names = append(names,
+			resourceName{Kind: "ReleasePlan", Name: application.ReleasePlanName(target), Owner: owner},
+			resourceName{Kind: "ServiceAccount", Name: application.ServiceAccountName(target), Owner: owner},
+			resourceName{Kind: "RoleBinding", Name: application.RoleBindingName(target), Owner: owner},
+		)
+	}
+	for _, c := range application.Components {
+		owner := fmt.Sprintf("component %s of %s (%s)", c.Name, c.Repository.Name, c.Version.Version)
+		names = append(names,
+			resourceName{Kind: "Component", Name: c.ResourceName(), Owner: owner},
+			resourceName{Kind: "PipelineRun", Name: c.PipelineRunName("pull-request"), Owner: owner},
+			resourceName{Kind: "PipelineRun", Name: c.PipelineRunName("push"), Owner: owner},
+			resourceName{Kind: "ServiceAccount", Name: c.BuildServiceAccountName(), Owner: owner},
+			// The image repositories are shared by the versions of a component
+			resourceName{Kind: "ImageRepository", Name: c.ImageRepositoryName(), Owner: fmt.Sprintf("component %s of %s", c.Name, c.Repository.Name)},
+		)
+	}
+	return names
+}
+
+// CheckResourceNames checks that the names of the resources generated for the applications are unique
+// and short enough, so that they can be computed before generating anything.
+func CheckResourceNames(applications []Application) error {
+	owners := map[string]string{}
+	reported := map[string]bool{}
+	errs := []string{}
+	for _, application := range applications {
+		for _, r := range resourceNames(application) {
+			key := r.Kind + "/" + r.Name
+			if owner, ok := owners[key]; ok {
+				msg := fmt.Sprintf("%s %q is generated for both %s and %s", r.Kind, r.Name, owner, r.Owner)
+				if owner != r.Owner && !reported[msg] {
+					errs = append(errs, msg)
+					reported[msg] = true
+				}
+				continue
+			}
+			owners[key] = r.Owner
+			if len(r.Name) > maxNameLength {
+				errs = append(errs, fmt.Sprintf("%s %q of %s is longer than %d characters (set shorten-names to shorten it)", r.Kind, r.Name, r.Owner, maxNameLength))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid resource names:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package konflux
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShortenName(t *testing.T) {
+	short := "tektoncd-cli-tkn-1-15"
+	if got := shortenName(short, maxNameLength); got != short {
+		t.Errorf("shortenName(%q) = %q, want the name unchanged", short, got)
+	}
+
+	long := "openshift-pipelines-tektoncd-pipeline-controller-1-15-on-pull-request"
+	got := shortenName(long, maxNameLength)
+	if len(got) > maxNameLength {
+		t.Errorf("shortenName(%q) = %q is longer than %d characters", long, got, maxNameLength)
+	}
+	if !strings.HasPrefix(got, long[:maxNameLength-hashLength-1]) {
+		t.Errorf("shortenName(%q) = %q doesn't keep the beginning of the name", long, got)
+	}
+	if again := shortenName(long, maxNameLength); again != got {
+		t.Errorf("shortenName(%q) is not stable: %q then %q", long, got, again)
+	}
+	if want := "openshift-pipelines-tektoncd-pipeline-controller-1-15-0d61f572"; got != want {
+		t.Errorf("shortenName(%q) = %q, want %q", long, got, want)
+	}
+
+	// Names only differing after the truncation don't collide
+	other := strings.TrimSuffix(long, "request") + "requests"
+	if shortenName(other, maxNameLength) == got {
+		t.Errorf("%q and %q are shortened to the same name %q", long, other, got)
+	}
+}
+
+func TestCheckResourceNames(t *testing.T) {
+	app := testApplication(t)
+	if err := CheckResourceNames([]Application{*app}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both application names are hyphenized to the same resource names
+	other := testApplication(t)
+	other.Name = "pipelines_"
+	app.Name = "pipelines-"
+	err := CheckResourceNames([]Application{*app, *other})
+	if err == nil {
+		t.Fatal("expected a collision")
+	}
+	want := `Application "pipelines--1-15" is generated for both application pipelines- (1.15) and application pipelines_ (1.15)`
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("error %q doesn't contain %q", err, want)
+	}
+
+	// The build ServiceAccount is the first name over the limit
+	app = testApplication(t)
+	app.Components[0].Name = "tkn-" + strings.Repeat("x", 30)
+	err = CheckResourceNames([]Application{*app})
+	if err == nil || !strings.Contains(err.Error(), `ServiceAccount "build-pipeline-tektoncd-cli-tkn-`) || !strings.Contains(err.Error(), "is longer than 63 characters") {
+		t.Fatalf("expected the build ServiceAccount name to be too long, got %v", err)
+	}
+	app.ShortenNames = true
+	if err := CheckResourceNames([]Application{*app}); err != nil {
+		t.Fatal(err)
+	}
+	if name := app.Components[0].BuildServiceAccountName(); len(name) > maxNameLength {
+		t.Errorf("build ServiceAccount %q is longer than %d characters", name, maxNameLength)
+	}
+}
+
+func TestReleaseImage(t *testing.T) {
+	digest := "@sha256:" + strings.Repeat("ab", 32)
+	app := testApplication(t)
+	app.Release.ReleaseImage = "ghcr.io/openshift-pipelines-konflux/hack/release:v0.1.0" + digest
+	if got := app.ReleaseImage(ReleaseTarget{}); got != app.Release.ReleaseImage {
+		t.Errorf("release image = %q, want the one of the release version", got)
+	}
+	target := ReleaseTarget{Name: "prod", ReleaseImage: "ghcr.io/openshift-pipelines-konflux/hack/release" + digest}
+	if got := app.ReleaseImage(target); got != target.ReleaseImage {
+		t.Errorf("release image = %q, want the one of the target", got)
+	}
+
+	for _, image := range []string{"", app.Release.ReleaseImage, target.ReleaseImage} {
+		if err := ValidateReleaseImage(image); err != nil {
+			t.Errorf("unexpected error for %q: %v", image, err)
+		}
+	}
+	for _, image := range []string{
+		"ghcr.io/openshift-pipelines-konflux/hack/release:v0.1.0",
+		"ghcr.io/openshift-pipelines-konflux/hack/release:latest",
+		"ghcr.io/openshift-pipelines-konflux/hack/release@sha256:abc",
+		digest,
+	} {
+		if err := ValidateReleaseImage(image); err == nil || !strings.Contains(err.Error(), "must be pinned by digest") {
+			t.Errorf("expected %q to be rejected, got %v", image, err)
+		}
+	}
+}
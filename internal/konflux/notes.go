@@ -0,0 +1,195 @@
+package konflux
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// headFile is the file of the downstream repositories recording the upstream commit of the sources,
+// updated by the update-sources workflow.
+const headFile = "head"
+
+// upstreamCommit is a commit pulled in from the upstream repository.
+type upstreamCommit struct {
+	Hash    string
+	Subject string
+}
+
+// upstreamChanges are the upstream commits pulled in a downstream repository between two releases.
+type upstreamChanges struct {
+	Repository Repository
+	// From and To are the upstream commits recorded in the head file at the previous release and on the
+	// release branch, empty when no head is recorded.
+	From    string
+	To      string
+	Commits []upstreamCommit
+}
+
+// ReleaseNotes returns the Markdown release notes of the applications of a release: the upstream commits
+// pulled in each repository since the previous release tag, found from the history of the head file. The
+// repositories are cloned in workDir.
+func ReleaseNotes(ctx context.Context, applications []Application, previous string, workDir string) (string, error) {
+	out := &bytes.Buffer{}
+	for i, application := range applications {
+		if i == 0 {
+			fmt.Fprintf(out, "# Release %s\n", application.Release.PatchVersion)
+		}
+		fmt.Fprintf(out, "\n## %s\n", application.Name)
+		for _, repo := range application.Repositories {
+			changes, err := repositoryChanges(ctx, repo, previous, workDir)
+			if err != nil {
+				return "", fmt.Errorf("failed to collect the changes of %s: %w", repo.Name, err)
+			}
+			writeChanges(out, changes)
+		}
+	}
+	return out.String(), nil
+}
+
+func writeChanges(out *bytes.Buffer, changes upstreamChanges) {
+	repo := changes.Repository
+	components := []string{}
+	for _, c := range repo.Components {
+		components = append(components, c.Name)
+	}
+	fmt.Fprintf(out, "\n### %s\n\n", repo.Name)
+	if len(components) > 0 {
+		fmt.Fprintf(out, "Components: %s\n\n", strings.Join(components, ", "))
+	}
+	switch {
+	case repo.Upstream == "":
+		fmt.Fprintf(out, "No upstream repository.\n")
+		return
+	case changes.To == "":
+		fmt.Fprintf(out, "No upstream commit recorded in %s.\n", headFile)
+		return
+	case changes.From == changes.To:
+		fmt.Fprintf(out, "No upstream changes, still at %s@%s.\n", repo.Upstream, shortHash(changes.To))
+		return
+	case changes.From == "":
+		fmt.Fprintf(out, "Upstream %s up to %s.\n", repo.Upstream, shortHash(changes.To))
+		return
+	default:
+		fmt.Fprintf(out, "Upstream [%s@%s...%s](https://github.com/%s/compare/%s...%s):\n\n", repo.Upstream, shortHash(changes.From), shortHash(changes.To), repo.Upstream, changes.From, changes.To)
+	}
+	for _, c := range changes.Commits {
+		fmt.Fprintf(out, "- %s (%s)\n", c.Subject, shortHash(c.Hash))
+	}
+}
+
+// repositoryChanges collects the upstream commits of a repository, between the head recorded at the
+// previous release tag (or the first recorded head) and the head of the release branch.
+func repositoryChanges(ctx context.Context, repo Repository, previous string, workDir string) (upstreamChanges, error) {
+	changes := upstreamChanges{Repository: repo}
+	if repo.Upstream == "" {
+		return changes, nil
+	}
+	dir := filepath.Join(workDir, "downstream", repo.Name)
+	if err := fetchRepository(ctx, repo.Url, dir); err != nil {
+		return changes, err
+	}
+	branch := "origin/" + repo.Branch.Name
+	to, err := recordedHead(ctx, dir, branch)
+	if err != nil || to == "" {
+		return changes, err
+	}
+	changes.To = to
+
+	if previous != "" {
+		if _, err := run(ctx, dir, "git", "rev-parse", "--verify", "--quiet", "refs/tags/"+previous); err == nil {
+			if changes.From, err = recordedHead(ctx, dir, "refs/tags/"+previous); err != nil {
+				return changes, err
+			}
+		} else {
+			log.Printf("[%s] Tag %s not found, using the first recorded head", repo.Name, previous)
+		}
+	}
+	if changes.From == "" {
+		// The oldest head recorded on the release branch
+		out, err := run(ctx, dir, "git", "log", "--reverse", "--format=%H", branch, "--", headFile)
+		if err != nil {
+			return changes, fmt.Errorf("failed to read the history of %s: %s, %s", headFile, err, out)
+		}
+		for _, commit := range strings.Fields(string(out)) {
+			if changes.From, err = recordedHead(ctx, dir, commit); err != nil {
+				return changes, err
+			}
+			if changes.From != "" {
+				break
+			}
+		}
+	}
+	if changes.From == "" || changes.From == changes.To {
+		return changes, nil
+	}
+
+	upstreamDir := filepath.Join(workDir, "upstream", repo.Upstream)
+	if err := fetchRepository(ctx, fmt.Sprintf("https://github.com/%s.git", repo.Upstream), upstreamDir); err != nil {
+		return changes, err
+	}
+	revisions := changes.From + ".." + changes.To
+	out, err := run(ctx, upstreamDir, "git", "log", "--no-merges", "--format=%H%x09%s", revisions)
+	if err != nil {
+		return changes, fmt.Errorf("failed to list the upstream commits %s: %s, %s", revisions, err, out)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if hash, subject, ok := strings.Cut(line, "\t"); ok {
+			changes.Commits = append(changes.Commits, upstreamCommit{Hash: hash, Subject: subject})
+		}
+	}
+	return changes, nil
+}
+
+// recordedHead returns the upstream commit recorded in the head file at a revision, or an empty string
+// when there is no head file at this revision.
+func recordedHead(ctx context.Context, dir, revision string) (string, error) {
+	// ls-tree lists nothing when the file doesn't exist at the revision, but fails on an unknown revision
+	out, err := run(ctx, dir, "git", "ls-tree", "--name-only", revision, "--", headFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up %s at %s: %s, %s", headFile, revision, err, out)
+	}
+	if strings.TrimSpace(string(out)) == "" {
+		return "", nil
+	}
+	out, err = run(ctx, dir, "git", "show", revision+":"+headFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s at %s: %s, %s", headFile, revision, err, out)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// fetchRepository clones the repository without checkout, and fetches its branches and tags.
+func fetchRepository(ctx context.Context, url, dir string) error {
+	exists, err := exists(filepath.Join(dir, "HEAD"))
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+			return err
+		}
+		if out, err := run(ctx, filepath.Dir(dir), "git", "clone", "--bare", url, filepath.Base(dir)); err != nil {
+			return fmt.Errorf("failed to clone %s: %s, %s", url, err, out)
+		}
+		// Bare clones don't have remote-tracking branches, they are fetched like in a regular clone
+		if out, err := run(ctx, dir, "git", "config", "remote.origin.fetch", "+refs/heads/*:refs/remotes/origin/*"); err != nil {
+			return fmt.Errorf("failed to configure %s: %s, %s", url, err, out)
+		}
+	}
+	if out, err := run(ctx, dir, "git", "fetch", "--prune", "--prune-tags", "--tags", "origin"); err != nil {
+		return fmt.Errorf("failed to fetch %s: %s, %s", url, err, out)
+	}
+	return nil
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
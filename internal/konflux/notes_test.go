@@ -0,0 +1,160 @@
+package konflux
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// gitSandbox isolates git from the user configuration and returns a directory where the github.com
+// repositories are served from: https://github.com/<org>/<name>.git is <dir>/<org>/<name>.git.
+func gitSandbox(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	config := filepath.Join(root, "gitconfig")
+	content := fmt.Sprintf("[url %q]\n\tinsteadOf = https://github.com/\n[init]\n\tdefaultBranch = main\n", root+"/")
+	if err := os.WriteFile(config, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", config)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	return root
+}
+
+// testGit runs git in dir and returns its trimmed output.
+func testGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := run(context.Background(), dir, "git", args...)
+	if err != nil {
+		t.Fatalf("git %v: %v, %s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// initRepository creates a repository in dir with its first branch named branch.
+func initRepository(t *testing.T, dir, branch string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	testGit(t, dir, "init", "--initial-branch", branch)
+}
+
+// commitFile commits the file with the given content and returns the hash of the commit.
+func commitFile(t *testing.T, dir, name, content, message string) string {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	testGit(t, dir, "add", name)
+	testGit(t, dir, "commit", "-m", message)
+	return testGit(t, dir, "rev-parse", "HEAD")
+}
+
+func TestReleaseNotes(t *testing.T) {
+	ctx := context.Background()
+	root := gitSandbox(t)
+
+	upstream := filepath.Join(root, "tektoncd", "cli.git")
+	initRepository(t, upstream, "main")
+	u1 := commitFile(t, upstream, "main.go", "1\n", "Initial commit")
+	u2 := commitFile(t, upstream, "main.go", "2\n", "Add the bundle command")
+	u3 := commitFile(t, upstream, "main.go", "3\n", "Fix the log output")
+	u4 := commitFile(t, upstream, "main.go", "4\n", "Add the export command")
+
+	// The head file is added after the first commit, updated to u2 at v1.15.0 and to u4 at v1.15.1
+	downstream := filepath.Join(root, "openshift-pipelines", "tektoncd-cli")
+	initRepository(t, downstream, "release-v1.15.x")
+	initial := commitFile(t, downstream, "README.md", "tektoncd-cli\n", "Initial commit")
+	commitFile(t, downstream, headFile, u1+"\n", "Update to "+u1)
+	commitFile(t, downstream, headFile, u2+"\n", "Update to "+u2)
+	testGit(t, downstream, "tag", "v1.15.0")
+	commitFile(t, downstream, headFile, u4+"\n", "Update to "+u4)
+	testGit(t, downstream, "tag", "v1.15.1")
+
+	app := testApplication(t)
+	app.Repositories[0].Url = downstream
+
+	tests := []struct {
+		name     string
+		previous string
+		from     string
+		commits  []string
+	}{{
+		name:    "first release",
+		from:    u1,
+		commits: []string{u4, u3, u2},
+	}, {
+		name:     "head changed since the previous release",
+		previous: "v1.15.0",
+		from:     u2,
+		commits:  []string{u4, u3},
+	}, {
+		name:     "previous release not tagged",
+		previous: "v1.14.0",
+		from:     u1,
+		commits:  []string{u4, u3, u2},
+	}, {
+		name:     "head unchanged since the previous release",
+		previous: "v1.15.1",
+		from:     u4,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := repositoryChanges(ctx, app.Repositories[0], tt.previous, t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changes.From != tt.from || changes.To != u4 {
+				t.Errorf("changes from %s to %s, want from %s to %s", changes.From, changes.To, tt.from, u4)
+			}
+			hashes := []string{}
+			for _, c := range changes.Commits {
+				hashes = append(hashes, c.Hash)
+			}
+			if len(tt.commits) == 0 {
+				tt.commits = []string{}
+			}
+			if !reflect.DeepEqual(hashes, tt.commits) {
+				t.Errorf("commits %v, want %v", hashes, tt.commits)
+			}
+		})
+	}
+
+	notes, err := ReleaseNotes(ctx, []Application{*app}, "v1.15.0", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf(`# Release 1.15.1
+
+## pipelines
+
+### tektoncd-cli
+
+Components: tkn
+
+Upstream [tektoncd/cli@%s...%s](https://github.com/tektoncd/cli/compare/%s...%s):
+
+- Add the export command (%s)
+- Fix the log output (%s)
+`, u2[:12], u4[:12], u2, u4, u4[:12], u3[:12])
+	if notes != want {
+		t.Errorf("release notes:\n%s\nwant:\n%s", notes, want)
+	}
+
+	// No head file before the second commit, but an unknown revision is an error
+	if head, err := recordedHead(ctx, downstream, initial); err != nil || head != "" {
+		t.Errorf("recorded head at %s = %q, %v, want no head", initial, head, err)
+	}
+	if _, err := recordedHead(ctx, downstream, "refs/tags/v1.16.0"); err == nil {
+		t.Error("expected an error for an unknown revision")
+	}
+}
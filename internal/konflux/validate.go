@@ -0,0 +1,286 @@
+package konflux
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+//go:embed schemas/*.yaml
+var schemaFS embed.FS
+
+// schema is the subset of the OpenAPI v3 schema used to validate the generated resources.
+type schema struct {
+	Type                  string
+	Required              []string
+	Properties            map[string]*schema
+	Items                 *schema
+	Enum                  []interface{}
+	Pattern               string
+	Nullable              bool
+	MinLength             *int `yaml:"minLength"`
+	MaxLength             *int `yaml:"maxLength"`
+	PreserveUnknownFields bool `yaml:"x-kubernetes-preserve-unknown-fields"`
+
+	// pattern is Pattern compiled when the schema is loaded.
+	pattern *regexp.Regexp
+}
+
+type resourceSchema struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string
+	Schema     *schema
+}
+
+// schemas are the bundled schemas by apiVersion/kind.
+var schemas = mustLoadSchemas()
+
+func mustLoadSchemas() map[string]*schema {
+	s, err := loadSchemas()
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func loadSchemas() (map[string]*schema, error) {
+	files, err := schemaFS.ReadDir("schemas")
+	if err != nil {
+		return nil, err
+	}
+	schemas := map[string]*schema{}
+	for _, f := range files {
+		in, err := schemaFS.ReadFile(path.Join("schemas", f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		r := resourceSchema{}
+		if err := yaml.UnmarshalStrict(in, &r); err != nil {
+			return nil, fmt.Errorf("error while parsing schema %s: %w", f.Name(), err)
+		}
+		if err := r.Schema.compile(""); err != nil {
+			return nil, fmt.Errorf("invalid schema %s: %w", f.Name(), err)
+		}
+		schemas[r.APIVersion+"/"+r.Kind] = r.Schema
+	}
+	return schemas, nil
+}
+
+// validateManifests parses every YAML document of content and validates the Kubernetes resources
+// against the bundled schemas, as well as their names and labels.
+func validateManifests(content []byte) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for i := 0; ; i++ {
+		var doc interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("document %d is not valid YAML: %w", i, err)
+		}
+		if doc == nil {
+			continue
+		}
+		obj, ok := normalize(doc).(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("document %d is not a YAML object", i)
+		}
+		apiVersion, _ := obj["apiVersion"].(string)
+		kind, _ := obj["kind"].(string)
+		if apiVersion == "" || kind == "" {
+			// Not a Kubernetes resource (GitHub workflows for example)
+			continue
+		}
+		errs := validateMetadata(obj)
+		if s, ok := schemas[apiVersion+"/"+kind]; ok {
+			errs = append(errs, s.validate("", obj)...)
+		}
+		if len(errs) > 0 {
+			metadata, _ := obj["metadata"].(map[string]interface{})
+			name, _ := metadata["name"].(string)
+			return fmt.Errorf("invalid %s %q: %s", kind, name, strings.Join(errs, ", "))
+		}
+	}
+}
+
+func validateMetadata(obj map[string]interface{}) []string {
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		return []string{"metadata is required"}
+	}
+	errs := []string{}
+	name, _ := metadata["name"].(string)
+	for _, msg := range validation.IsDNS1123Subdomain(name) {
+		errs = append(errs, fmt.Sprintf("metadata.name %q: %s", name, msg))
+	}
+	labels, _ := metadata["labels"].(map[string]interface{})
+	for _, key := range sortedKeys(labels) {
+		value := fmt.Sprint(labels[key])
+		for _, msg := range validation.IsValidLabelValue(value) {
+			errs = append(errs, fmt.Sprintf("metadata.labels.%s %q: %s", key, value, msg))
+		}
+	}
+	return errs
+}
+
+// compile compiles the patterns of the schema and its properties.
+func (s *schema) compile(field string) error {
+	if s == nil {
+		return nil
+	}
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid pattern: %w", fieldName(field), err)
+		}
+		s.pattern = pattern
+	}
+	keys := make([]string, 0, len(s.Properties))
+	for key := range s.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := s.Properties[key].compile(join(field, key)); err != nil {
+			return err
+		}
+	}
+	return s.Items.compile(field + "[]")
+}
+
+func (s *schema) validate(field string, value interface{}) []string {
+	if s == nil {
+		return nil
+	}
+	if value == nil && s.Nullable {
+		return nil
+	}
+	errs := []string{}
+	switch s.Type {
+	case "":
+		// any type
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected object, got %s", fieldName(field), typeName(value))}
+		}
+		for _, r := range s.Required {
+			if _, ok := obj[r]; !ok {
+				errs = append(errs, fmt.Sprintf("%s is required", fieldName(join(field, r))))
+			}
+		}
+		for _, key := range sortedKeys(obj) {
+			p, ok := s.Properties[key]
+			if !ok {
+				if !s.PreserveUnknownFields {
+					errs = append(errs, fmt.Sprintf("%s: unknown field", fieldName(join(field, key))))
+				}
+				continue
+			}
+			errs = append(errs, p.validate(join(field, key), obj[key])...)
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected array, got %s", fieldName(field), typeName(value))}
+		}
+		for i, item := range items {
+			errs = append(errs, s.Items.validate(fmt.Sprintf("%s[%d]", field, i), item)...)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected string, got %s", fieldName(field), typeName(value))}
+		}
+		if s.MinLength != nil && len(str) < *s.MinLength {
+			errs = append(errs, fmt.Sprintf("%s: must be at least %d characters", fieldName(field), *s.MinLength))
+		}
+		if s.MaxLength != nil && len(str) > *s.MaxLength {
+			errs = append(errs, fmt.Sprintf("%s %q: must be at most %d characters", fieldName(field), str, *s.MaxLength))
+		}
+		if s.pattern != nil && !s.pattern.MatchString(str) {
+			errs = append(errs, fmt.Sprintf("%s %q: must match %s", fieldName(field), str, s.Pattern))
+		}
+	case "integer":
+		if _, ok := value.(int); !ok {
+			return []string{fmt.Sprintf("%s: expected integer, got %s", fieldName(field), typeName(value))}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{fmt.Sprintf("%s: expected boolean, got %s", fieldName(field), typeName(value))}
+		}
+	default:
+		return []string{fmt.Sprintf("%s: unknown schema type %s", fieldName(field), s.Type)}
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if reflect.DeepEqual(e, value) {
+				found = true
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Sprintf("%s %v: must be one of %v", fieldName(field), value, s.Enum))
+		}
+	}
+	return errs
+}
+
+// normalize converts the maps decoded by yaml.v2 to map[string]interface{}.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for k, value := range v {
+			m[fmt.Sprint(k)] = normalize(value)
+		}
+		return m
+	case []interface{}:
+		for i := range v {
+			v[i] = normalize(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func join(field, key string) string {
+	if field == "" {
+		return key
+	}
+	return field + "." + key
+}
+
+func fieldName(field string) string {
+	if field == "" {
+		return "<root>"
+	}
+	return field
+}
+
+func typeName(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	return reflect.TypeOf(v).String()
+}
@@ -0,0 +1,124 @@
+package konflux
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestSchemaCompile(t *testing.T) {
+	in := `
+type: object
+properties:
+  spec:
+    type: object
+    properties:
+      names:
+        type: array
+        items:
+          type: string
+          pattern: "^[a-z]+$"
+`
+	s := &schema{}
+	if err := yaml.UnmarshalStrict([]byte(in), s); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.compile(""); err != nil {
+		t.Fatal(err)
+	}
+	obj := map[string]interface{}{"spec": map[string]interface{}{"names": []interface{}{"ok", "Not-OK"}}}
+	errs := s.validate("", obj)
+	if len(errs) != 1 || !strings.Contains(errs[0], `spec.names[1] "Not-OK": must match ^[a-z]+$`) {
+		t.Errorf("unexpected errors %v", errs)
+	}
+
+	s.Properties["spec"].Properties["names"].Items.Pattern = "^[a-z"
+	err := s.compile("")
+	if err == nil || !strings.Contains(err.Error(), "spec.names[]: invalid pattern") {
+		t.Errorf("expected an invalid pattern error, got %v", err)
+	}
+}
+
+func TestLoadSchemas(t *testing.T) {
+	if _, err := loadSchemas(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenerateFileFromTemplateRejectsInvalidManifests(t *testing.T) {
+	dir := t.TempDir()
+	overrides := map[string]string{
+		"labelled-component.yaml": `---
+apiVersion: appstudio.redhat.com/v1alpha1
+kind: Component
+metadata:
+  name: {{.ResourceName}}
+  labels:
+    app.kubernetes.io/version: {{.ResourceName}}-{{.ResourceName}}-{{.ResourceName}}-{{.ResourceName}}
+spec:
+  componentName: {{.ComponentName}}
+  application: {{.Application}}
+`,
+		"orphan-component.yaml": `---
+apiVersion: appstudio.redhat.com/v1alpha1
+kind: Component
+metadata:
+  name: {{.ResourceName}}
+spec:
+  componentName: {{.ComponentName}}
+`,
+	}
+	for name, content := range overrides {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(view *ComponentView)
+		want   string
+	}{{
+		name:   "invalid name",
+		mutate: func(view *ComponentView) { view.ResourceName = "---controller" },
+		want:   `invalid Component "---controller": metadata.name "---controller": a lowercase RFC 1123 subdomain`,
+	}, {
+		name: "label value too long",
+		mutate: func(view *ComponentView) {
+			view.overrides = map[string]string{"component.yaml": filepath.Join(dir, "labelled-component.yaml")}
+		},
+		want: "metadata.labels.app.kubernetes.io/version \"tektoncd-cli-tkn-1-15-tektoncd-cli-tkn-1-15-tektoncd-cli-tkn-1-15-tektoncd-cli-tkn-1-15\": must be no more than 63 characters",
+	}, {
+		name: "missing required spec field",
+		mutate: func(view *ComponentView) {
+			view.overrides = map[string]string{"component.yaml": filepath.Join(dir, "orphan-component.yaml")}
+		},
+		want: `invalid Component "tektoncd-cli-tkn-1-15": spec.application is required`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view, err := newComponentView(testApplication(t).Components[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.mutate(&view)
+			file := filepath.Join(t.TempDir(), "component.yaml")
+			err = generateFileFromTemplate("component.yaml", view, file)
+			if err == nil {
+				t.Fatal("expected the rendered manifest to be rejected")
+			}
+			for _, want := range []string{"template component.yaml for component tkn: ", tt.want} {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q doesn't contain %q", err, want)
+				}
+			}
+			if _, err := os.Stat(file); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("expected %s not to be written, got %v", file, err)
+			}
+		})
+	}
+}
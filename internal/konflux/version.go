@@ -0,0 +1,197 @@
+package konflux
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// The development versions, built from the main branches.
+const (
+	VersionMain = "main"
+	VersionNext = "next"
+)
+
+var releaseVersionPattern = regexp.MustCompile(`^([0-9]+)\.([0-9]+)(?:\.([0-9]+))?$`)
+
+// ReleaseVersion is the version of a release: main and next for the development versions, X.Y for a
+// minor release stream or X.Y.Z for a patch version.
+type ReleaseVersion struct {
+	// development is main or next, empty for the numbered versions.
+	development string
+	Major       int
+	Minor       int
+	Patch       int
+	// HasPatch is set for the X.Y.Z versions.
+	HasPatch bool
+	// set distinguishes a parsed version, e.g. 0.0, from the zero value.
+	set bool
+}
+
+// ParseReleaseVersion parses main, next, X.Y or X.Y.Z.
+func ParseReleaseVersion(version string) (ReleaseVersion, error) {
+	if version == VersionMain || version == VersionNext {
+		return ReleaseVersion{development: version, set: true}, nil
+	}
+	m := releaseVersionPattern.FindStringSubmatch(version)
+	if m == nil {
+		return ReleaseVersion{}, fmt.Errorf("invalid version %q: expected main, next, X.Y or X.Y.Z", version)
+	}
+	v := ReleaseVersion{set: true}
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		v.Patch, _ = strconv.Atoi(m[3])
+		v.HasPatch = true
+	}
+	return v, nil
+}
+
+// String returns the version as configured, or an empty string if the version is not set.
+func (v ReleaseVersion) String() string {
+	switch {
+	case !v.set:
+		return ""
+	case v.development != "":
+		return v.development
+	case v.HasPatch:
+		return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	default:
+		return v.MajorMinor()
+	}
+}
+
+// IsDevelopment reports whether the version is main or next.
+func (v ReleaseVersion) IsDevelopment() bool {
+	return v.development != ""
+}
+
+// MajorMinor returns X.Y, or the version itself for main and next.
+func (v ReleaseVersion) MajorMinor() string {
+	if v.IsDevelopment() {
+		return v.development
+	}
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// Hyphenized returns the version usable in resource names, e.g. 1-22.
+func (v ReleaseVersion) Hyphenized() string {
+	return hyphenize(v.String())
+}
+
+// Branch returns the downstream branch of the version: main for the development versions,
+// release-vX.Y.x for a release stream and release-vX.Y.Z for a patch version.
+func (v ReleaseVersion) Branch() string {
+	switch {
+	case v.IsDevelopment():
+		return "main"
+	case v.HasPatch:
+		return "release-v" + v.String()
+	default:
+		return "release-v" + v.String() + ".x"
+	}
+}
+
+// Compare returns -1, 0 or +1 as v is older, the same or newer than o. The numbered versions are
+// older than next, itself older than main, and X.Y is older than its patch versions.
+func (v ReleaseVersion) Compare(o ReleaseVersion) int {
+	if v.IsDevelopment() || o.IsDevelopment() {
+		return compareInts(developmentRank(v), developmentRank(o))
+	}
+	for _, c := range []int{compareInts(v.Major, o.Major), compareInts(v.Minor, o.Minor), compareBools(v.HasPatch, o.HasPatch), compareInts(v.Patch, o.Patch)} {
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// PreviousPatch returns the patch version released before v (1.22.0 for 1.22.1), false for the first
+// patch version of a release.
+func (v ReleaseVersion) PreviousPatch() (ReleaseVersion, bool, error) {
+	if !v.HasPatch {
+		return ReleaseVersion{}, false, fmt.Errorf("%q is not a patch version (X.Y.Z)", v)
+	}
+	if v.Patch == 0 {
+		return ReleaseVersion{}, false, nil
+	}
+	v.Patch--
+	return v, true, nil
+}
+
+// NextPatch returns the patch version following v (1.22.1 for 1.22.0).
+func (v ReleaseVersion) NextPatch() (ReleaseVersion, error) {
+	if !v.HasPatch {
+		return ReleaseVersion{}, fmt.Errorf("%q is not a patch version (X.Y.Z)", v)
+	}
+	v.Patch++
+	return v, nil
+}
+
+// Patch returns the patch version of a release, which must be a patch version (X.Y.Z) of the release
+// version. The development versions have no patch version, only a label such as nightly.
+func (r Release) Patch() (ReleaseVersion, error) {
+	if r.Version.IsDevelopment() {
+		return ReleaseVersion{}, fmt.Errorf("%s is a development version, only release versions have patch versions", r.Version)
+	}
+	patch, err := ParseReleaseVersion(r.PatchVersion)
+	if err != nil {
+		return ReleaseVersion{}, err
+	}
+	if !patch.HasPatch || patch.MajorMinor() != r.Version.MajorMinor() {
+		return ReleaseVersion{}, fmt.Errorf("%s is not a patch version of %s", r.PatchVersion, r.Version)
+	}
+	return patch, nil
+}
+
+// UnmarshalYAML parses the version of a release file.
+func (v *ReleaseVersion) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	version, err := ParseReleaseVersion(s)
+	if err != nil {
+		return err
+	}
+	*v = version
+	return nil
+}
+
+// MarshalText returns the version as configured.
+func (v ReleaseVersion) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+func developmentRank(v ReleaseVersion) int {
+	switch v.development {
+	case VersionNext:
+		return 1
+	case VersionMain:
+		return 2
+	default:
+		return 0
+	}
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareBools(a, b bool) int {
+	return compareInts(boolToInt(a), boolToInt(b))
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,66 @@
+package konflux
+
+import (
+	"testing"
+)
+
+func TestReleaseVersion(t *testing.T) {
+	tests := []struct {
+		version    string
+		majorMinor string
+		branch     string
+		hyphenized string
+	}{
+		{"main", "main", "main", "main"},
+		{"next", "next", "main", "next"},
+		{"0.0", "0.0", "release-v0.0.x", "0-0"},
+		{"0.0.0", "0.0", "release-v0.0.0", "0-0-0"},
+		{"1.22", "1.22", "release-v1.22.x", "1-22"},
+		{"1.22.3", "1.22", "release-v1.22.3", "1-22-3"},
+	}
+	for _, tt := range tests {
+		v, err := ParseReleaseVersion(tt.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.String() != tt.version || v.MajorMinor() != tt.majorMinor || v.Branch() != tt.branch || v.Hyphenized() != tt.hyphenized {
+			t.Errorf("%s: got %s, %s, %s, %s", tt.version, v, v.MajorMinor(), v.Branch(), v.Hyphenized())
+		}
+	}
+	if s := (ReleaseVersion{}).String(); s != "" {
+		t.Errorf("zero version = %q, want empty", s)
+	}
+	for _, invalid := range []string{"", "1", "v1.22", "1.22.x", "nightly"} {
+		if _, err := ParseReleaseVersion(invalid); err == nil {
+			t.Errorf("expected %q to be invalid", invalid)
+		}
+	}
+}
+
+func TestReleaseVersionPatches(t *testing.T) {
+	first, err := ParseReleaseVersion("0.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := first.PreviousPatch(); ok || err != nil {
+		t.Errorf("expected no patch version before 0.1.0, got %v, %v", ok, err)
+	}
+	next, err := first.NextPatch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.String() != "0.1.1" || next.Compare(first) != 1 || first.Compare(next) != -1 {
+		t.Errorf("unexpected next patch %s", next)
+	}
+	previous, ok, err := next.PreviousPatch()
+	if err != nil || !ok || previous.String() != "0.1.0" || previous.Compare(first) != 0 {
+		t.Errorf("unexpected previous patch %s, %v, %v", previous, ok, err)
+	}
+	stream, err := ParseReleaseVersion("0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.NextPatch(); err == nil {
+		t.Error("expected an error for the next patch of a release stream")
+	}
+}
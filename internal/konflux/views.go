@@ -0,0 +1,241 @@
+package konflux
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The templates are executed with views: flat structures computed once from the configuration, so that
+// the names, images and references are built in Go instead of being repeated across the templates.
+
+// view is the data a template is executed with.
+type view interface {
+	source() generated
+}
+
+// generated is embedded in the views: the application the files are generated for, and the templates
+// overriding the embedded ones.
+type generated struct {
+	// ApplicationName is the name of the application, without the version.
+	ApplicationName string
+	templateDir     string
+	overrides       map[string]string
+}
+
+func (g generated) source() generated {
+	return g
+}
+
+// ApplicationView is the data of the application templates (application, tests, release plan, ...).
+type ApplicationView struct {
+	generated
+	// Name is the Application resource name.
+	Name        string
+	DisplayName string
+	// Version is the release version, e.g. 1.22 or next.
+	Version      string
+	PatchVersion string
+	// Branch is the downstream branch of the version.
+	Branch string
+	// EnterpriseContract is the name of the enterprise contract IntegrationTestScenario.
+	EnterpriseContract string
+	// Policy is the enterprise contract policy, for containers or indexes.
+	Policy string
+	// ServiceAccount, RoleBinding, ReleasePlan, ReleaseSecret, AutoRelease and ReleaseToGitHub are the ones of
+	// the default release target, or of the release target of a ReleasePlanView.
+	ServiceAccount  string
+	RoleBinding     string
+	ReleasePlan     string
+	ReleaseSecret   string
+	AutoRelease     bool
+	ReleaseToGitHub bool
+}
+
+// ReleasePlanView is the data of the templates of a release target (release plan, service account and role).
+type ReleasePlanView struct {
+	ApplicationView
+	// PipelineRevision is the revision of the release pipeline.
+	PipelineRevision string
+	// ReleaseImage is the cmd/release image run by the release pipeline, pinned by digest.
+	ReleaseImage string
+	// ReleaseParams are the parameters of the release pipeline, besides release_version and release_to_github.
+	ReleaseParams []Param
+}
+
+// TestScenarioView is the data of the IntegrationTestScenario templates (tests.yaml for the enterprise
+// contract scenario, release-tests.yaml for the pipeline ones).
+type TestScenarioView struct {
+	ApplicationView
+	// ScenarioName is the IntegrationTestScenario resource name.
+	ScenarioName string
+	// Scenario is the configured scenario, with its policy defaulted.
+	Scenario TestScenario
+}
+
+// ComponentView is the data of the component templates (component, image repository and build PipelineRuns).
+type ComponentView struct {
+	generated
+	// Name is the component name as configured, e.g. index-4.15.
+	Name string
+	// ComponentName is the spec.componentName of the Component.
+	ComponentName string
+	// ResourceName is the Component resource name.
+	ResourceName string
+	// Application is the Application resource name.
+	Application     string
+	ImageRepository string
+	// Image is the name of the image in the registry, and ImageURL its full reference without tag.
+	Image    string
+	ImageURL string
+	// Nudges are the names of the components nudged by this component.
+	Nudges        []string
+	GitURL        string
+	Branch        string
+	Dockerfile    string
+	PrefetchInput string
+	// WatchedSources is the CEL expression of the changed files triggering a build.
+	WatchedSources       string
+	NudgeFiles           string
+	PipelineRunComponent string
+	BuildServiceAccount  string
+	PullRequest          PipelineRunView
+	Push                 PipelineRunView
+	Build                Build
+	Namespace            string
+	SBOMWebhook          string
+}
+
+// PipelineRunView is a build PipelineRun of a component.
+type PipelineRunView struct {
+	Name string
+	// File is the path of the PipelineRun in the repository.
+	File string
+}
+
+// RepositoryView is the data of the GitHub workflow templates.
+type RepositoryView struct {
+	generated
+	Name           string
+	Upstream       string
+	Branch         string
+	UpstreamBranch string
+	UpdateSources  string
+	// GitHub is the configuration of the workflows, with its defaults.
+	GitHub   GitHub
+	Patches  []Patch
+	BotName  string
+	BotEmail string
+}
+
+func newApplicationView(a Application) ApplicationView {
+	policy := a.Tenant.Policy + "-containers"
+	if strings.Contains(a.Name, "index") {
+		policy = a.Tenant.Policy + "-indexes"
+	}
+	return ApplicationView{
+		generated:          generated{ApplicationName: a.Name, templateDir: a.TemplateDir},
+		Name:               a.ResourceName(),
+		DisplayName:        hyphenize(a.Name + "-" + a.Release.Version.String()),
+		Version:            a.Release.Version.String(),
+		Branch:             a.Release.Version.Branch(),
+		PatchVersion:       a.Release.PatchVersion,
+		EnterpriseContract: a.EnterpriseContractName(),
+		Policy:             policy,
+		ServiceAccount:     a.ServiceAccountName(ReleaseTarget{}),
+		RoleBinding:        a.RoleBindingName(ReleaseTarget{}),
+		ReleasePlan:        a.ReleasePlanName(ReleaseTarget{}),
+		ReleaseSecret:      a.Tenant.ReleaseSecret,
+		AutoRelease:        true,
+	}
+}
+
+func newReleasePlanView(a Application, target ReleaseTarget) ReleasePlanView {
+	v := ReleasePlanView{
+		ApplicationView:  newApplicationView(a),
+		PipelineRevision: a.PipelineRevision(target),
+		ReleaseImage:     a.ReleaseImage(target),
+	}
+	v.ServiceAccount = a.ServiceAccountName(target)
+	v.RoleBinding = a.RoleBindingName(target)
+	v.ReleasePlan = a.ReleasePlanName(target)
+	v.AutoRelease = target.AutoRelease == nil || *target.AutoRelease
+	v.ReleaseToGitHub = target.GitHub
+	if target.Secret != "" {
+		v.ReleaseSecret = target.Secret
+	}
+	if target.Registry != "" {
+		v.ReleaseParams = append(v.ReleaseParams, Param{Name: "target_registry", Value: target.Registry})
+	}
+	v.ReleaseParams = append(v.ReleaseParams, target.Params...)
+	return v
+}
+
+func newTestScenarioView(a Application, scenario TestScenario) TestScenarioView {
+	v := TestScenarioView{
+		ApplicationView: newApplicationView(a),
+		ScenarioName:    a.TestScenarioName(scenario.Name),
+		Scenario:        scenario,
+	}
+	if v.Scenario.Policy == "" {
+		v.Scenario.Policy = v.Policy
+	}
+	return v
+}
+
+func newComponentView(c Component) (ComponentView, error) {
+	nudges := []string{}
+	for _, nudge := range c.Nudges {
+		// An empty nudge disables the default one
+		if nudge == "" {
+			continue
+		}
+		n, err := Eval(nudge, c)
+		if err != nil {
+			return ComponentView{}, fmt.Errorf("failed to evaluate nudge %q of component %s: %w", nudge, c.Name, err)
+		}
+		nudges = append(nudges, n)
+	}
+	if len(c.Nudges) == 0 {
+		nudges = append(nudges, fmt.Sprintf("tektoncd-operator-%s-bundle", c.Version.Version.Hyphenized()))
+	}
+	file := fmt.Sprintf("%s/%s-%s-%s", tektonDir, hyphenize(basename(c.Repository.Name)), c.Version.Version.Hyphenized(), c.Name)
+	return ComponentView{
+		generated:            generated{ApplicationName: c.Application.Name, templateDir: c.Application.TemplateDir, overrides: c.Repository.Templates},
+		Name:                 c.Name,
+		ComponentName:        hyphenize(c.Name),
+		ResourceName:         c.ResourceName(),
+		Application:          c.Application.ResourceName(),
+		ImageRepository:      c.ImageRepositoryName(),
+		Image:                c.imageName(),
+		ImageURL:             c.BuildImage(),
+		Nudges:               nudges,
+		GitURL:               c.Repository.Url,
+		Branch:               c.Repository.Branch.Name,
+		Dockerfile:           c.Dockerfile,
+		PrefetchInput:        c.PrefetchInput,
+		WatchedSources:       c.Tekton.WatchedSources,
+		NudgeFiles:           c.Tekton.NudgeFiles,
+		PipelineRunComponent: c.PipelineRunComponent(),
+		BuildServiceAccount:  c.BuildServiceAccountName(),
+		PullRequest:          PipelineRunView{Name: c.PipelineRunName("pull-request"), File: file + "-pull-request.yaml"},
+		Push:                 PipelineRunView{Name: c.PipelineRunName("push"), File: file + "-push.yaml"},
+		Build:                c.Build,
+		Namespace:            c.Application.Tenant.Namespace,
+		SBOMWebhook:          c.Application.Tenant.SBOMWebhook,
+	}, nil
+}
+
+func newRepositoryView(r Repository) RepositoryView {
+	return RepositoryView{
+		generated:      generated{ApplicationName: r.Application.Name, templateDir: r.Application.TemplateDir, overrides: r.Templates},
+		Name:           r.Name,
+		Upstream:       r.Upstream,
+		Branch:         r.Branch.Name,
+		UpstreamBranch: r.Branch.UpstreamBranch,
+		UpdateSources:  r.GitHub.UpdateSources,
+		GitHub:         r.GitHub,
+		Patches:        r.Patches,
+		BotName:        r.Application.Tenant.BotName,
+		BotEmail:       r.Application.Tenant.BotEmail,
+	}
+}
@@ -3,15 +3,18 @@ package prowgen
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	gyaml "github.com/ghodss/yaml"
+	"github.com/openshift-pipelines-konflux/hack/internal/forge"
 	cioperatorapi "github.com/openshift/ci-tools/pkg/api"
 	"gopkg.in/yaml.v2"
 	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
@@ -26,11 +29,34 @@ type Repository struct {
 	OpenShift          OpenShift          `json:"openshift" yaml:"openshift"`
 	OpenShiftPipelines OpenShiftPipelines `json:"openshift-pipelines" yaml:"openshift-pipelines"`
 	E2E                E2E                `json:"e2e" yaml:"e2e"`
+	Tests              []E2E              `json:"tests" yaml:"tests"`
 	GolangVersion      string             `json:"golang" yaml:"golang"`
+	RHEL               string             `json:"rhel" yaml:"rhel"`
+	Platform           `json:",inline" yaml:",inline"`
 }
 
 type E2E struct {
-	Workflow string `json:"workflow" yaml:"workflow"`
+	Workflow  string            `json:"workflow" yaml:"workflow"`
+	As        string            `json:"as" yaml:"as"`
+	Commands  string            `json:"commands" yaml:"commands"`
+	Resources map[string]string `json:"resources" yaml:"resources"`
+	Post      []string          `json:"post" yaml:"post"`
+	Cron      string            `json:"cron" yaml:"cron"`
+	Matrix    Matrix            `json:"matrix" yaml:"matrix"`
+	Platform  `json:",inline" yaml:",inline"`
+}
+
+// Platform configures where the tests are running, it can be set per repository and overridden per test.
+type Platform struct {
+	Cluster      string `json:"cluster" yaml:"cluster"`
+	Cloud        string `json:"cloud" yaml:"cloud"`
+	Architecture string `json:"architecture" yaml:"architecture"`
+}
+
+// Matrix fans out a test across multiple OpenShift versions and architectures.
+type Matrix struct {
+	OpenShift     []string `json:"openshift" yaml:"openshift"`
+	Architectures []string `json:"architectures" yaml:"architectures"`
 }
 
 type OpenShift struct {
@@ -49,40 +75,56 @@ type ReleaseBuildconfiguration struct {
 func Main() {
 	ctx := context.TODO()
 
-	inputConfig := flag.String("config", filepath.Join("config", "repository.yaml"), "Specify repository config")
+	var inputConfigs configFlags
+	flag.Var(&inputConfigs, "config", "Specify repository config file or directory of config files (can be repeated)")
 	outConfig := flag.String("output", filepath.Join("repos", "openshift", "release", "ci-operator", "config"), "Specify repositories config")
 	remote := flag.String("remote", "", "openshift/release remote fork (example: git@github.com:pierDipi/release.git)")
 	branch := flag.String("branch", "sync-openshift-pipelines-ci", "Branch for remote fork")
+	base := flag.String("base", "master", "openshift/release branch the configurations are generated from and the pull-request is opened against")
 	podman := flag.Bool("podman", false, "Use podman instead of docker")
+	githubAPI := flag.String("github-api", forge.DefaultGitHubURL, "GitHub API endpoint used to open the openshift/release pull-request")
+	renderOnly := flag.Bool("render-only", false, "Only write the ci-operator configurations in the output directory, without cloning openshift/release")
+	releaseCheckout := flag.String("release-checkout", "", "Local openshift/release checkout to diff the rendered configurations against (with --render-only)")
 	flag.Parse()
 
-	log.Println(*inputConfig, *outConfig, *remote, *branch)
+	inputConfigs = append(inputConfigs, flag.Args()...)
+	if len(inputConfigs) == 0 {
+		inputConfigs = configFlags{filepath.Join("config", "repository.yaml")}
+	}
 
-	in, err := os.ReadFile(*inputConfig)
+	log.Println(inputConfigs, *outConfig, *remote, *branch)
+
+	repos, err := ReadRepositories(inputConfigs)
 	if err != nil {
 		log.Fatalln(err)
 	}
-	repo := &Repository{}
-	if err := yaml.UnmarshalStrict(in, repo); err != nil {
-		log.Fatalln("Unmarshal input config", err)
-	}
-	if len(repo.Branches) == 0 {
-		repo.Branches = []string{"main"}
-	}
 
 	// Generate configurations
-	cfgs, err := GenerateReleaseBuildConfigurationFromConfig(repo)
-	if err != nil {
-		log.Fatalln(err)
+	cfgs := []ReleaseBuildconfiguration{}
+	for _, repo := range repos {
+		repoCfgs, err := GenerateReleaseBuildConfigurationFromConfig(repo)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		cfgs = append(cfgs, repoCfgs...)
+	}
+
+	if *renderOnly {
+		if err := RenderOnly(ctx, repos, cfgs, *outConfig, *releaseCheckout); err != nil {
+			log.Fatalln(err)
+		}
+		return
 	}
 
 	// Clone openshift/release
-	if err := InitializeOpenShiftReleaseRepository(ctx, "openshift/release", repo, outConfig); err != nil {
+	if err := InitializeOpenShiftReleaseRepository(ctx, "openshift/release", *base); err != nil {
 		log.Fatalln(err)
 	}
 	// Remove existing configuration
-	if err := DeleteReleaseConfiguration("openshift/release", repo, *outConfig); err != nil {
-		log.Fatalln(err)
+	for _, repo := range repos {
+		if err := DeleteReleaseConfiguration("openshift/release", repo, *outConfig); err != nil {
+			log.Fatalln(err)
+		}
 	}
 
 	// Add new configuration
@@ -96,38 +138,185 @@ func Main() {
 	}
 
 	// Commit and push
-	if err := PushBranch(ctx, repositoryDirectory("openshift/release"), remote, *branch, *inputConfig); err != nil {
+	changed, err := PushBranch(ctx, repositoryDirectory("openshift/release"), remote, *branch, commitMessage(repos))
+	if err != nil {
 		log.Fatalln(err)
 	}
+	if !changed || *remote == "" {
+		return
+	}
+
+	// Open or update the pull-request against openshift/release
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	if token == "" {
+		log.Println("No GITHUB_TOKEN set, skipping pull-request")
+		return
+	}
+	if err := OpenPullRequest(ctx, forge.NewGitHub(*githubAPI, token), "openshift/release", *base, *remote, *branch, repos); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// configFlags collects repeated --config flags.
+type configFlags []string
+
+func (c *configFlags) String() string {
+	return strings.Join(*c, ",")
 }
 
-func PushBranch(ctx context.Context, release string, remote *string, branch string, config string) error {
+func (c *configFlags) Set(value string) error {
+	*c = append(*c, value)
+	return nil
+}
+
+// ReadRepositories reads the repository configurations from the given paths.
+// A path can either be a single config file or a directory, in which case every
+// YAML file in it is read.
+func ReadRepositories(paths []string) ([]*Repository, error) {
+	files := []string{}
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(p, "*.yaml"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+
+	repos := []*Repository{}
+	seen := map[string]string{}
+	for _, file := range files {
+		in, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		repo := &Repository{}
+		if err := yaml.UnmarshalStrict(in, repo); err != nil {
+			return nil, fmt.Errorf("unmarshal input config %s: %w", file, err)
+		}
+		if len(repo.Branches) == 0 {
+			repo.Branches = []string{"main"}
+		}
+		if previous, ok := seen[repo.Repo]; ok {
+			return nil, fmt.Errorf("repository %s is configured in both %s and %s", repo.Repo, previous, file)
+		}
+		seen[repo.Repo] = file
+		repos = append(repos, repo)
+	}
+	if len(repos) == 0 {
+		return nil, fmt.Errorf("no repository configuration found in %v", paths)
+	}
+	return repos, nil
+}
+
+func commitMessage(repos []*Repository) string {
+	return pullRequestTitle(repos) + "\n\n" + pullRequestBody(repos)
+}
+
+func pullRequestTitle(repos []*Repository) string {
+	names := []string{}
+	for _, repo := range repos {
+		names = append(names, repo.Repo)
+	}
+	return "Sync OpenShift Pipelines CI: " + strings.Join(names, ", ")
+}
+
+func pullRequestBody(repos []*Repository) string {
+	var sb strings.Builder
+	sb.WriteString("Synced repositories and branches:\n\n")
+	for _, repo := range repos {
+		sb.WriteString(fmt.Sprintf("- openshift-pipelines/%s: %s\n", repo.Repo, strings.Join(repo.Branches, ", ")))
+	}
+	sb.WriteString("\nThis PR was automatically generated by the prowgen command from openshift-pipelines/hack repository\n")
+	return sb.String()
+}
+
+// PushBranch commits the changes and pushes them to the remote fork, it returns
+// false when there is nothing to commit.
+func PushBranch(ctx context.Context, release string, remote *string, branch string, message string) (bool, error) {
 	// Ignore error since remote and branch might be already there
 	_, _ = run(ctx, release, "git", "checkout", "-b", branch)
 	_, _ = run(ctx, release, "git", "checkout", branch)
 
 	if _, err := run(ctx, release, "git", "add", "."); err != nil {
-		return err
+		return false, err
+	}
+	out, err := run(ctx, release, "git", "status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+	if strings.TrimSpace(string(out)) == "" {
+		log.Println("No changes, skipping commit and push")
+		return false, nil
 	}
-	if _, err := run(ctx, release, "git", "commit", "-m", "Sync OpenShift Pipelines CI "+config); err != nil {
-		// Ignore error since we could have nothing to commit
-		log.Println("Ignored error", err)
+	if _, err := run(ctx, release, "git", "commit", "-m", message); err != nil {
+		return false, err
 	}
 
 	if remote == nil || *remote == "" {
-		return nil
+		return true, nil
 	}
 
 	log.Println("Pushing branch", branch, "to", *remote)
 
 	_, _ = run(ctx, release, "git", "remote", "add", "fork", *remote)
 	if _, err := run(ctx, release, "git", "push", "fork", fmt.Sprintf("%s:%s", branch, branch), "-f"); err != nil {
-		return err
+		return false, err
 	}
 
+	return true, nil
+}
+
+// OpenPullRequest finds or creates the pull-request from the fork branch to the base branch of
+// openShiftRelease and updates its title and body with the synced repositories.
+func OpenPullRequest(ctx context.Context, client forge.Client, openShiftRelease string, base string, remote string, branch string, repos []*Repository) error {
+	owner, err := forkOwner(remote)
+	if err != nil {
+		return err
+	}
+	pr, err := forge.EnsurePullRequest(ctx, client, openShiftRelease, forge.PullRequest{
+		Title: pullRequestTitle(repos),
+		Body:  pullRequestBody(repos),
+		Head:  owner + ":" + branch,
+		Base:  base,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open pull-request against %s: %w", openShiftRelease, err)
+	}
+	log.Println("Pull-request", pr.URL)
 	return nil
 }
 
+// forkRemotePrefixes are the github.com remote URL forms forkOwner supports.
+var forkRemotePrefixes = []string{"git@github.com:", "ssh://git@github.com/", "https://github.com/"}
+
+// forkOwner returns the owner of the fork from its remote (git@github.com:owner/release.git,
+// ssh://git@github.com/owner/release.git or https://github.com/owner/release.git).
+func forkOwner(remote string) (string, error) {
+	for _, prefix := range forkRemotePrefixes {
+		if !strings.HasPrefix(remote, prefix) {
+			continue
+		}
+		parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(remote, prefix), ".git"), "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return "", fmt.Errorf("remote %q is not a github.com repository: expected %sowner/repository.git", remote, prefix)
+		}
+		return parts[0], nil
+	}
+	return "", fmt.Errorf("remote %q is not a supported github.com URL: expected one of %sowner/repository.git", remote, strings.Join(forkRemotePrefixes, "owner/repository.git, "))
+}
+
 func filenameFromRepoAndBranch(repo *Repository, branch string) string {
 	return fmt.Sprintf("openshift-pipelines/%s/openshift-pipelines-%s-%s.yaml", repo.Repo, repo.Repo, branch)
 }
@@ -143,198 +332,207 @@ func RunOpenShiftReleaseGenerator(ctx context.Context, openShiftRelease string,
 	return nil
 }
 
+// DeleteReleaseConfiguration removes every existing configuration of the repository,
+// including the ones for branches that are not part of Repository.Branches anymore,
+// so that the openshift/release tree mirrors our configuration once the new one is saved.
 func DeleteReleaseConfiguration(openShiftRelease string, repo *Repository, outConfig string) error {
 	paths := []string{}
 	for _, b := range repo.Branches {
 		paths = append(paths, filepath.Join(outConfig, filenameFromRepoAndBranch(repo, b)))
 	}
+	stale, err := filepath.Glob(filepath.Join(outConfig, filepath.Dir(filenameFromRepoAndBranch(repo, "")), fmt.Sprintf("openshift-pipelines-%s-*.yaml", repo.Repo)))
+	if err != nil {
+		return err
+	}
+	paths = append(paths, stale...)
 	for _, p := range paths {
 		log.Println("Remove", p)
-		if err := os.Remove(p); err != nil {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
 			return err
 		}
 	}
 	return nil
 }
 
+// GenerateReleaseBuildConfigurationFromConfig returns the ci-operator configurations of each branch of the
+// repository. The tests running on another OpenShift version than the repository one (see `matrix.openshift`)
+// are generated in a variant per version, with the build root and the releases of that version.
 func GenerateReleaseBuildConfigurationFromConfig(repo *Repository) ([]ReleaseBuildconfiguration, error) {
-	dockerfilepath := repo.BaseDockerfile
-	if dockerfilepath == "" {
-		dockerfilepath = "ci/ci.Dockerfile"
-	}
 	tests, err := generateTestFromConfig(repo)
 	if err != nil {
 		return nil, err
 	}
+	testsPerVersion := map[string][]cioperatorapi.TestStepConfiguration{}
+	versions := []string{repo.OpenShift.Version}
+	for _, test := range tests {
+		version := repo.OpenShift.Version
+		if test.ClusterClaim != nil {
+			version = test.ClusterClaim.Version
+		}
+		if _, ok := testsPerVersion[version]; !ok && version != repo.OpenShift.Version {
+			versions = append(versions, version)
+		}
+		testsPerVersion[version] = append(testsPerVersion[version], test)
+	}
+	sort.Strings(versions[1:])
+
 	configs := []ReleaseBuildconfiguration{}
 	for _, b := range repo.Branches {
-		configs = append(configs, ReleaseBuildconfiguration{
-			Filename: filenameFromRepoAndBranch(repo, b),
-			ReleaseBuildConfiguration: cioperatorapi.ReleaseBuildConfiguration{
-				InputConfiguration: cioperatorapi.InputConfiguration{
-					BaseImages: map[string]cioperatorapi.ImageStreamTagReference{
-						fmt.Sprintf("openshift_release_golang-%s", repo.GolangVersion): {
-							Name:      "release",
-							Namespace: "openshift",
-							Tag:       fmt.Sprintf("golang-%s", repo.GolangVersion),
-						},
-					},
-					BuildRootImage: &cioperatorapi.BuildRootImageConfiguration{
-						ImageStreamTagReference: &cioperatorapi.ImageStreamTagReference{
-							Name:      "builder",
-							Namespace: "ocp",
-							Tag:       fmt.Sprintf("rhel-8-golang-%s-openshift-%s", repo.GolangVersion, repo.OpenShift.Version),
-						},
+		for _, version := range versions {
+			variant := ""
+			if version != repo.OpenShift.Version {
+				variant = "ocp" + k8sNameString(version)
+			}
+			configs = append(configs, releaseBuildConfiguration(repo, b, version, variant, testsPerVersion[version]))
+		}
+	}
+	return configs, nil
+}
+
+// releaseBuildConfiguration returns the ci-operator configuration of a branch, running the tests against
+// the given OpenShift version.
+func releaseBuildConfiguration(repo *Repository, branch string, ocpVersion string, variant string, tests []cioperatorapi.TestStepConfiguration) ReleaseBuildconfiguration {
+	dockerfilepath := repo.BaseDockerfile
+	if dockerfilepath == "" {
+		dockerfilepath = "ci/ci.Dockerfile"
+	}
+	rhel := repo.RHEL
+	if rhel == "" {
+		rhel = "8"
+	}
+	if tests == nil {
+		tests = []cioperatorapi.TestStepConfiguration{}
+	}
+	filename := filenameFromRepoAndBranch(repo, branch)
+	if variant != "" {
+		filename = strings.TrimSuffix(filename, ".yaml") + "__" + variant + ".yaml"
+	}
+	return ReleaseBuildconfiguration{
+		Filename: filename,
+		ReleaseBuildConfiguration: cioperatorapi.ReleaseBuildConfiguration{
+			InputConfiguration: cioperatorapi.InputConfiguration{
+				BaseImages: map[string]cioperatorapi.ImageStreamTagReference{
+					fmt.Sprintf("openshift_release_golang-%s", repo.GolangVersion): {
+						Name:      "release",
+						Namespace: "openshift",
+						Tag:       fmt.Sprintf("golang-%s", repo.GolangVersion),
 					},
-					Releases: map[string]cioperatorapi.UnresolvedRelease{
-						"initial": {
-							Integration: &cioperatorapi.Integration{
-								Name:      repo.OpenShift.Version,
-								Namespace: "ocp",
-							},
-						},
-						"latest": {
-							Integration: &cioperatorapi.Integration{
-								Name:               repo.OpenShift.Version,
-								IncludeBuiltImages: true,
-								Namespace:          "ocp",
-							},
-						},
+				},
+				BuildRootImage: &cioperatorapi.BuildRootImageConfiguration{
+					ImageStreamTagReference: &cioperatorapi.ImageStreamTagReference{
+						Name:      "builder",
+						Namespace: "ocp",
+						Tag:       fmt.Sprintf("rhel-%s-golang-%s-openshift-%s", rhel, repo.GolangVersion, ocpVersion),
 					},
 				},
-				Images: []cioperatorapi.ProjectDirectoryImageBuildStepConfiguration{{
-					ProjectDirectoryImageBuildInputs: cioperatorapi.ProjectDirectoryImageBuildInputs{
-						DockerfilePath: dockerfilepath,
-						Inputs: map[string]cioperatorapi.ImageBuildInputs{
-							fmt.Sprintf("openshift_release_golang-%s", repo.GolangVersion): {
-								As: []string{fmt.Sprintf("registry.ci.openshift.org/openshift/release:golang-%s", repo.GolangVersion)},
-							},
+				Releases: map[string]cioperatorapi.UnresolvedRelease{
+					"initial": {
+						Integration: &cioperatorapi.Integration{
+							Name:      ocpVersion,
+							Namespace: "ocp",
 						},
 					},
-					To: cioperatorapi.PipelineImageStreamTagReference("base-tests"),
-				}},
-				Resources: cioperatorapi.ResourceConfiguration{
-					"*": cioperatorapi.ResourceRequirements{
-						Requests: cioperatorapi.ResourceList{
-							"cpu":    "500m",
-							"memory": "1Gi",
+					"latest": {
+						Integration: &cioperatorapi.Integration{
+							Name:               ocpVersion,
+							IncludeBuiltImages: true,
+							Namespace:          "ocp",
 						},
 					},
 				},
-				Tests: tests,
-				Metadata: cioperatorapi.Metadata{
-					Org:    "openshift-pipelines",
-					Repo:   repo.Repo,
-					Branch: b,
-				},
 			},
-		})
-	}
-	return configs, nil
-}
-
-func generateTestFromConfig(repo *Repository) ([]cioperatorapi.TestStepConfiguration, error) {
-	tests := []cioperatorapi.TestStepConfiguration{}
-	clusterClaim := getClusterClaim(repo.OpenShift.Version)
-	switch repo.E2E.Workflow {
-	case "tasks":
-		for _, version := range repo.OpenShiftPipelines.Versions {
-			version := version
-			tests = append(tests, cioperatorapi.TestStepConfiguration{
-				As:           fmt.Sprintf("osp-%s-ocp-%s-e2e", k8sNameString(version), k8sNameString(repo.OpenShift.Version)),
-				Cluster:      "build05",
-				ClusterClaim: clusterClaim,
-				MultiStageTestConfiguration: &cioperatorapi.MultiStageTestConfiguration{
-					AllowSkipOnSuccess:       pTrue(),
-					AllowBestEffortPostSteps: pTrue(),
-					Post:                     getTaskPostSteps(),
-					Test: []cioperatorapi.TestStep{{
-						LiteralTestStep: &cioperatorapi.LiteralTestStep{
-							As:       "e2e",
-							Cli:      "latest",
-							Commands: fmt.Sprintf("make OSP_VERSION=%s test-e2e-openshift", version),
-							From:     "base-tests",
-							Resources: cioperatorapi.ResourceRequirements{
-								Requests: cioperatorapi.ResourceList{
-									"cpu": "100m",
-								},
-							},
+			Images: []cioperatorapi.ProjectDirectoryImageBuildStepConfiguration{{
+				ProjectDirectoryImageBuildInputs: cioperatorapi.ProjectDirectoryImageBuildInputs{
+					DockerfilePath: dockerfilepath,
+					Inputs: map[string]cioperatorapi.ImageBuildInputs{
+						fmt.Sprintf("openshift_release_golang-%s", repo.GolangVersion): {
+							As: []string{fmt.Sprintf("registry.ci.openshift.org/openshift/release:golang-%s", repo.GolangVersion)},
 						},
-					}},
-					Workflow: stringPtr("generic-claim"),
-				},
-				SkipIfOnlyChanged: skipIfOnlyChanged,
-			})
-		}
-	default:
-		return tests, fmt.Errorf("unknown workflow %q", repo.E2E.Workflow)
-	}
-	return tests, nil
-}
-
-func getTaskPostSteps() []cioperatorapi.TestStep {
-	return []cioperatorapi.TestStep{{
-		LiteralTestStep: &cioperatorapi.LiteralTestStep{
-			As:                "openshift-pipelines-must-gather",
-			BestEffort:        pTrue(),
-			OptionalOnSuccess: pFalse(),
-			Cli:               "latest",
-			Commands:          "oc adm must-gather --image=quay.io/openshift-pipeline/must-gather --dest-dir \"${ARTIFACT_DIR}/gather-openshift-pipelines\"",
-			From:              "base-tests",
-			Resources: cioperatorapi.ResourceRequirements{
-				Requests: cioperatorapi.ResourceList{
-					"cpu": "100m",
+					},
 				},
-			},
-			Timeout: &prowv1.Duration{time.Duration(20) * time.Minute},
-		},
-	}, {
-		LiteralTestStep: &cioperatorapi.LiteralTestStep{
-			As:                "openshift-must-gather",
-			BestEffort:        pTrue(),
-			OptionalOnSuccess: pFalse(),
-			Cli:               "latest",
-			Commands:          "oc adm must-gather --dest-dir \"${ARTIFACT_DIR}/gather-openshift\"",
-			From:              "base-tests",
-			Resources: cioperatorapi.ResourceRequirements{
-				Requests: cioperatorapi.ResourceList{
-					"cpu": "100m",
+				To: cioperatorapi.PipelineImageStreamTagReference("base-tests"),
+			}},
+			Resources: cioperatorapi.ResourceConfiguration{
+				"*": cioperatorapi.ResourceRequirements{
+					Requests: cioperatorapi.ResourceList{
+						"cpu":    "500m",
+						"memory": "1Gi",
+					},
 				},
 			},
-			Timeout: &prowv1.Duration{time.Duration(20) * time.Minute},
-		},
-	}, {
-		LiteralTestStep: &cioperatorapi.LiteralTestStep{
-			As:                "openshift-gather-extra",
-			BestEffort:        pTrue(),
-			OptionalOnSuccess: pFalse(),
-			Cli:               "latest",
-			Commands:          "curl -skSL https://raw.githubusercontent.com/openshift/release/master/ci-operator/step-registry/gather/extra/gather-extra-commands.sh | /bin/bash -s",
-			From:              "base-tests",
-			GracePeriod:       &prowv1.Duration{time.Duration(1) * time.Minute},
-			Resources: cioperatorapi.ResourceRequirements{
-				Requests: cioperatorapi.ResourceList{
-					"cpu":    "300m",
-					"memory": "300Mi",
-				},
+			Tests: tests,
+			Metadata: cioperatorapi.Metadata{
+				Org:     "openshift-pipelines",
+				Repo:    repo.Repo,
+				Branch:  branch,
+				Variant: variant,
 			},
-			Timeout: &prowv1.Duration{time.Duration(20) * time.Minute},
 		},
-	}}
+	}
 }
 
-func getClusterClaim(ocpVersion string) *cioperatorapi.ClusterClaim {
+func getClusterClaim(ocpVersion string, platform Platform) *cioperatorapi.ClusterClaim {
 	return &cioperatorapi.ClusterClaim{
-		Architecture: "amd64",
+		Architecture: cioperatorapi.ReleaseArchitecture(platform.Architecture),
 		As:           "latest",
-		Cloud:        "openstack",
+		Cloud:        cioperatorapi.Cloud(platform.Cloud),
 		Owner:        "pipelines",
 		Product:      cioperatorapi.ReleaseProductOCP,
-		Timeout:      &prowv1.Duration{time.Duration(60) * time.Minute},
+		Timeout:      &prowv1.Duration{Duration: time.Duration(60) * time.Minute},
 		Version:      ocpVersion,
 	}
 }
 
+// getPlatform returns the platform of the test, falling back to the repository one and then to the defaults.
+func getPlatform(repo *Repository, e2e E2E) Platform {
+	platform := Platform{Cluster: "build05", Cloud: "openstack", Architecture: "amd64"}
+	for _, p := range []Platform{repo.Platform, e2e.Platform} {
+		if p.Cluster != "" {
+			platform.Cluster = p.Cluster
+		}
+		if p.Cloud != "" {
+			platform.Cloud = p.Cloud
+		}
+		if p.Architecture != "" {
+			platform.Architecture = p.Architecture
+		}
+	}
+	return platform
+}
+
+// target is a single entry of the test matrix.
+type target struct {
+	OpenShift string
+	Platform  Platform
+	// Suffix distinguishes the test names of the matrix entries.
+	Suffix string
+}
+
+// getTargets expands the test matrix, without matrix the test runs once on the repository OpenShift version.
+func getTargets(repo *Repository, e2e E2E) []target {
+	platform := getPlatform(repo, e2e)
+	versions := e2e.Matrix.OpenShift
+	if len(versions) == 0 {
+		versions = []string{repo.OpenShift.Version}
+	}
+	architectures := e2e.Matrix.Architectures
+	if len(architectures) == 0 {
+		architectures = []string{platform.Architecture}
+	}
+	targets := []target{}
+	for _, version := range versions {
+		for _, architecture := range architectures {
+			t := target{OpenShift: version, Platform: platform}
+			t.Platform.Architecture = architecture
+			if len(e2e.Matrix.Architectures) > 0 {
+				t.Suffix = "-" + k8sNameString(architecture)
+			}
+			targets = append(targets, t)
+		}
+	}
+	return targets
+}
+
 func k8sNameString(s string) string {
 	return strings.ReplaceAll(strings.ToLower(s), ".", "")
 }
@@ -363,13 +561,7 @@ func SaveReleaseBuildConfiguration(outConfig *string, cfgs []ReleaseBuildconfigu
 		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
 			return err
 		}
-		// Going directly from struct to YAML produces unexpected configs (due to missing YAML tags),
-		// so we produce JSON and then convert it to YAML.
-		out, err := json.Marshal(cfg.ReleaseBuildConfiguration)
-		if err != nil {
-			return err
-		}
-		out, err = gyaml.JSONToYAML(out)
+		out, err := RenderReleaseBuildConfiguration(cfg)
 		if err != nil {
 			return err
 		}
@@ -380,11 +572,22 @@ func SaveReleaseBuildConfiguration(outConfig *string, cfgs []ReleaseBuildconfigu
 	return nil
 }
 
-func InitializeOpenShiftReleaseRepository(ctx context.Context, openShiftRelease string, inConfig *Repository, outputConfig *string) error {
+// RenderReleaseBuildConfiguration returns the ci-operator configuration as it is saved in openshift/release.
+func RenderReleaseBuildConfiguration(cfg ReleaseBuildconfiguration) ([]byte, error) {
+	// Going directly from struct to YAML produces unexpected configs (due to missing YAML tags),
+	// so we produce JSON and then convert it to YAML.
+	out, err := json.Marshal(cfg.ReleaseBuildConfiguration)
+	if err != nil {
+		return nil, err
+	}
+	return gyaml.JSONToYAML(out)
+}
+
+func InitializeOpenShiftReleaseRepository(ctx context.Context, openShiftRelease string, base string) error {
 	if err := GitMirror(ctx, openShiftRelease); err != nil {
 		return err
 	}
-	if err := GitCheckout(ctx, openShiftRelease, "master"); err != nil {
+	if err := GitCheckout(ctx, openShiftRelease, base); err != nil {
 		return err
 	}
 	return nil
@@ -0,0 +1,277 @@
+package prowgen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/openshift-pipelines-konflux/hack/internal/forge"
+)
+
+func TestGenerateReleaseBuildConfigurationMatrixVariants(t *testing.T) {
+	repo := &Repository{
+		Repo:          "tool",
+		Branches:      []string{"main"},
+		OpenShift:     OpenShift{Version: "4.15"},
+		GolangVersion: "1.21",
+		Tests: []E2E{
+			{Workflow: "unit"},
+			{Workflow: "e2e", Matrix: Matrix{OpenShift: []string{"4.16", "4.15", "4.14"}}},
+		},
+	}
+	cfgs, err := GenerateReleaseBuildConfigurationFromConfig(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		filename string
+		variant  string
+		version  string
+		tests    []string
+	}{
+		{"openshift-pipelines/tool/openshift-pipelines-tool-main.yaml", "", "4.15", []string{"unit", "ocp-415-e2e"}},
+		{"openshift-pipelines/tool/openshift-pipelines-tool-main__ocp414.yaml", "ocp414", "4.14", []string{"ocp-414-e2e"}},
+		{"openshift-pipelines/tool/openshift-pipelines-tool-main__ocp416.yaml", "ocp416", "4.16", []string{"ocp-416-e2e"}},
+	}
+	if len(cfgs) != len(want) {
+		t.Fatalf("got %d configurations, want %d", len(cfgs), len(want))
+	}
+	for i, w := range want {
+		cfg := cfgs[i]
+		if cfg.Filename != w.filename || cfg.Metadata.Variant != w.variant {
+			t.Errorf("configuration %d: got %s (variant %q), want %s (variant %q)", i, cfg.Filename, cfg.Metadata.Variant, w.filename, w.variant)
+		}
+		if tag := cfg.BuildRootImage.ImageStreamTagReference.Tag; tag != "rhel-8-golang-1.21-openshift-"+w.version {
+			t.Errorf("%s: build root %s, want OpenShift %s", cfg.Filename, tag, w.version)
+		}
+		for _, name := range []string{"initial", "latest"} {
+			if got := cfg.Releases[name].Integration.Name; got != w.version {
+				t.Errorf("%s: release %s is %s, want %s", cfg.Filename, name, got, w.version)
+			}
+		}
+		names := []string{}
+		for _, test := range cfg.Tests {
+			names = append(names, test.As)
+		}
+		if !reflect.DeepEqual(names, w.tests) {
+			t.Errorf("%s: tests %v, want %v", cfg.Filename, names, w.tests)
+		}
+	}
+}
+
+// fakePullRequests is an httptest handler serving the pull-requests endpoints of a repository.
+type fakePullRequests struct {
+	t    *testing.T
+	repo string
+	prs  []forge.PullRequest
+	// requests records the method of each request.
+	requests []string
+}
+
+func (f *fakePullRequests) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.requests = append(f.requests, r.Method)
+	if got := r.Header.Get("Authorization"); got != "Bearer token" {
+		f.t.Errorf("unexpected Authorization header %q", got)
+	}
+	prefix := "/repos/" + f.repo + "/pulls"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.NotFound(w, r)
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == prefix:
+		found := []forge.PullRequest{}
+		for _, pr := range f.prs {
+			if pr.Head == r.URL.Query().Get("head") && pr.Base == r.URL.Query().Get("base") {
+				found = append(found, pr)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(found)
+	case r.Method == http.MethodPost && r.URL.Path == prefix:
+		pr := forge.PullRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&pr); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		pr.Number = len(f.prs) + 1
+		pr.URL = fmt.Sprintf("https://github.com/%s/pull/%d", f.repo, pr.Number)
+		f.prs = append(f.prs, pr)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(pr)
+	case r.Method == http.MethodPatch:
+		number, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, prefix+"/"))
+		if err != nil || number < 1 || number > len(f.prs) {
+			http.NotFound(w, r)
+			return
+		}
+		update := forge.PullRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		pr := &f.prs[number-1]
+		pr.Title, pr.Body = update.Title, update.Body
+		_ = json.NewEncoder(w).Encode(pr)
+	default:
+		http.Error(w, "unexpected request", http.StatusMethodNotAllowed)
+	}
+}
+
+func TestOpenPullRequest(t *testing.T) {
+	fake := &fakePullRequests{t: t, repo: "openshift/release"}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	client := forge.NewGitHub(server.URL, "token")
+	ctx := context.Background()
+
+	repos := []*Repository{{Repo: "tektoncd-cli", Branches: []string{"main"}}}
+	if err := OpenPullRequest(ctx, client, "openshift/release", "master", "git@github.com:someone/release.git", "sync", repos); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.prs) != 1 {
+		t.Fatalf("expected a pull-request to be created, got %v", fake.prs)
+	}
+	pr := fake.prs[0]
+	if pr.Head != "someone:sync" || pr.Base != "master" {
+		t.Errorf("unexpected head %q and base %q", pr.Head, pr.Base)
+	}
+	if pr.Title != "Sync OpenShift Pipelines CI: tektoncd-cli" {
+		t.Errorf("unexpected title %q", pr.Title)
+	}
+
+	// The existing pull-request is updated with the new repositories
+	repos = append(repos, &Repository{Repo: "tektoncd-operator", Branches: []string{"main", "release-v1.15.x"}})
+	if err := OpenPullRequest(ctx, client, "openshift/release", "master", "https://github.com/someone/release.git", "sync", repos); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.prs) != 1 {
+		t.Fatalf("expected the pull-request to be updated, got %v", fake.prs)
+	}
+	if got := fake.prs[0]; got.Title != "Sync OpenShift Pipelines CI: tektoncd-cli, tektoncd-operator" || !strings.Contains(got.Body, "openshift-pipelines/tektoncd-operator: main, release-v1.15.x") {
+		t.Errorf("pull-request not updated: %+v", got)
+	}
+	if want := []string{http.MethodGet, http.MethodPost, http.MethodGet, http.MethodPatch}; !reflect.DeepEqual(fake.requests, want) {
+		t.Errorf("requests = %v, want %v", fake.requests, want)
+	}
+
+	// Another fork opens its own pull-request
+	if err := OpenPullRequest(ctx, client, "openshift/release", "master", "git@github.com:other/release.git", "sync", repos); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.prs) != 2 || fake.prs[1].Head != "other:sync" {
+		t.Errorf("expected a pull-request from other:sync, got %v", fake.prs)
+	}
+
+	// The pull-request is opened against the requested base branch
+	if err := OpenPullRequest(ctx, client, "openshift/release", "main", "git@github.com:other/release.git", "sync", repos); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.prs) != 3 || fake.prs[2].Base != "main" {
+		t.Errorf("expected a pull-request against main, got %v", fake.prs)
+	}
+
+	if err := OpenPullRequest(ctx, client, "openshift/release", "master", "release", "sync", repos); err == nil {
+		t.Error("expected an error for a remote without owner")
+	}
+}
+
+func TestForkOwner(t *testing.T) {
+	tests := []struct {
+		remote string
+		owner  string
+		err    string
+	}{
+		{remote: "git@github.com:someone/release.git", owner: "someone"},
+		{remote: "git@github.com:someone/release", owner: "someone"},
+		{remote: "ssh://git@github.com/someone/release.git", owner: "someone"},
+		{remote: "https://github.com/someone/release.git", owner: "someone"},
+		{remote: "https://github.com/someone/release", owner: "someone"},
+		{remote: "https://github.com/someone", err: `remote "https://github.com/someone" is not a github.com repository`},
+		{remote: "git@github.com:/release.git", err: `remote "git@github.com:/release.git" is not a github.com repository`},
+		{remote: "https://gitlab.com/someone/release.git", err: `remote "https://gitlab.com/someone/release.git" is not a supported github.com URL`},
+		{remote: "release", err: `remote "release" is not a supported github.com URL`},
+		{remote: "", err: `remote "" is not a supported github.com URL`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.remote, func(t *testing.T) {
+			owner, err := forkOwner(tt.remote)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error %q, got %v (owner %q)", tt.err, err, owner)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if owner != tt.owner {
+				t.Errorf("owner = %q, want %q", owner, tt.owner)
+			}
+		})
+	}
+}
+
+func TestPushBranch(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	fork := filepath.Join(t.TempDir(), "fork.git")
+	if _, err := runNoRepo(ctx, "git", "init", "--bare", fork); err != nil {
+		t.Fatal(err)
+	}
+	release := t.TempDir()
+	if _, err := run(ctx, release, "git", "init"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(release, "README.md"), []byte("release\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run(ctx, release, "git", "add", "."); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run(ctx, release, "git", "commit", "-m", "initial"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing to commit, nothing is pushed
+	changed, err := PushBranch(ctx, release, &fork, "sync", "Sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed {
+		t.Error("expected no change without diff")
+	}
+	if _, err := run(ctx, fork, "git", "rev-parse", "--verify", "--quiet", "refs/heads/sync"); err == nil {
+		t.Error("expected the branch not to be pushed without diff")
+	}
+
+	if err := os.WriteFile(filepath.Join(release, "config.yaml"), []byte("tests: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	changed, err = PushBranch(ctx, release, &fork, "sync", "Sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Error("expected a change")
+	}
+	out, err := run(ctx, fork, "git", "log", "-1", "--format=%s", "sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(out)) != "Sync" {
+		t.Errorf("unexpected commit %q pushed", out)
+	}
+}
@@ -0,0 +1,97 @@
+package prowgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+)
+
+// RenderOnly writes the configurations of the given repositories in outConfig and, if a local
+// openshift/release checkout is given, prints the differences with the configurations in it.
+func RenderOnly(ctx context.Context, repos []*Repository, cfgs []ReleaseBuildconfiguration, outConfig string, releaseCheckout string) error {
+	for _, repo := range repos {
+		if err := DeleteReleaseConfiguration("", repo, outConfig); err != nil {
+			return err
+		}
+	}
+	if err := SaveReleaseBuildConfiguration(&outConfig, cfgs); err != nil {
+		return err
+	}
+	log.Println("Rendered", len(cfgs), "configurations in", outConfig)
+
+	if releaseCheckout == "" {
+		return nil
+	}
+	changed, err := DiffReleaseConfiguration(ctx, repos, outConfig, filepath.Join(releaseCheckout, "ci-operator", "config"))
+	if err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		log.Println("No difference with", releaseCheckout)
+		return nil
+	}
+	log.Println("Changed configurations:")
+	for _, f := range changed {
+		log.Println("-", f)
+	}
+	return nil
+}
+
+// DiffReleaseConfiguration prints a unified diff between the configurations of the given repositories
+// in the existing and the rendered directories and returns the files that differ.
+func DiffReleaseConfiguration(ctx context.Context, repos []*Repository, rendered string, existing string) ([]string, error) {
+	changed := []string{}
+	for _, repo := range repos {
+		repoDir := filepath.Dir(filenameFromRepoAndBranch(repo, ""))
+		files := map[string]struct{}{}
+		for _, dir := range []string{rendered, existing} {
+			matches, err := filepath.Glob(filepath.Join(dir, repoDir, fmt.Sprintf("openshift-pipelines-%s-*.yaml", repo.Repo)))
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range matches {
+				files[filepath.Join(repoDir, filepath.Base(m))] = struct{}{}
+			}
+		}
+		sorted := make([]string, 0, len(files))
+		for f := range files {
+			sorted = append(sorted, f)
+		}
+		sort.Strings(sorted)
+
+		for _, f := range sorted {
+			different, err := diff(ctx, filepath.Join(existing, f), filepath.Join(rendered, f))
+			if err != nil {
+				return nil, err
+			}
+			if different {
+				changed = append(changed, f)
+			}
+		}
+	}
+	return changed, nil
+}
+
+func diff(ctx context.Context, a string, b string) (bool, error) {
+	cmd := exec.CommandContext(ctx, "diff", "-u", "--new-file", a, b)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to diff %s and %s: %w", a, b, err)
+	}
+	return false, nil
+}
@@ -0,0 +1,118 @@
+package prowgen
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "Update the expected ci-operator configurations in testdata")
+
+// TestGenerateReleaseBuildConfigurationGolden renders the configuration of each testdata/<name>/config.yaml
+// and compares it with the ci-operator configurations in testdata/<name>/expected.
+func TestGenerateReleaseBuildConfigurationGolden(t *testing.T) {
+	configs, err := filepath.Glob(filepath.Join("testdata", "*", "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configs) == 0 {
+		t.Fatal("no configuration in testdata")
+	}
+	for _, config := range configs {
+		dir := filepath.Dir(config)
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			repos, err := ReadRepositories([]string{config})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfgs, err := GenerateReleaseBuildConfigurationFromConfig(repos[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			expectedDir := filepath.Join(dir, "expected")
+			if *update {
+				if err := os.RemoveAll(expectedDir); err != nil {
+					t.Fatal(err)
+				}
+				if err := SaveReleaseBuildConfiguration(&expectedDir, cfgs); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			expected := map[string]bool{}
+			err = filepath.WalkDir(expectedDir, func(path string, d os.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					rel, _ := filepath.Rel(expectedDir, path)
+					expected[rel] = true
+				}
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, cfg := range cfgs {
+				got, err := RenderReleaseBuildConfiguration(cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want, err := os.ReadFile(filepath.Join(expectedDir, cfg.Filename))
+				if err != nil {
+					t.Errorf("unexpected configuration %s (run go test -update): %v", cfg.Filename, err)
+					continue
+				}
+				delete(expected, cfg.Filename)
+				if string(got) != string(want) {
+					t.Errorf("%s differs from the expected configuration (run go test -update):\n%s", cfg.Filename, got)
+				}
+			}
+			for f := range expected {
+				t.Errorf("expected configuration %s is not generated", f)
+			}
+		})
+	}
+}
+
+func TestDiffReleaseConfiguration(t *testing.T) {
+	repos, err := ReadRepositories([]string{filepath.Join("testdata", "cli", "config.yaml")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgs, err := GenerateReleaseBuildConfigurationFromConfig(repos[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered := t.TempDir()
+	if err := SaveReleaseBuildConfiguration(&rendered, cfgs); err != nil {
+		t.Fatal(err)
+	}
+	existing := filepath.Join("testdata", "cli", "expected")
+
+	changed, err := DiffReleaseConfiguration(context.Background(), repos, rendered, existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 0 {
+		t.Errorf("expected no difference, got %v", changed)
+	}
+
+	stale := filepath.Join(rendered, cfgs[0].Filename)
+	if err := os.WriteFile(stale, []byte("tests: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	changed, err = DiffReleaseConfiguration(context.Background(), repos, rendered, existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 1 || !strings.HasSuffix(cfgs[0].Filename, changed[0]) {
+		t.Errorf("expected %s to differ, got %v", cfgs[0].Filename, changed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := DiffReleaseConfiguration(ctx, repos, rendered, existing); err == nil {
+		t.Error("expected the diff to fail with a cancelled context")
+	}
+}
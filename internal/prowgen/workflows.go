@@ -0,0 +1,267 @@
+package prowgen
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	cioperatorapi "github.com/openshift/ci-tools/pkg/api"
+	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+)
+
+// Workflow generates the tests of a repository for a given e2e configuration.
+type Workflow func(repo *Repository, e2e E2E) ([]cioperatorapi.TestStepConfiguration, error)
+
+var workflows = map[string]Workflow{
+	"tasks":    tasksWorkflow,
+	"unit":     unitWorkflow,
+	"e2e":      e2eWorkflow,
+	"periodic": periodicWorkflow,
+}
+
+// RegisterWorkflow makes a workflow available to the `e2e.workflow` and `tests[].workflow` fields.
+func RegisterWorkflow(name string, w Workflow) {
+	workflows[name] = w
+}
+
+func generateTestFromConfig(repo *Repository) ([]cioperatorapi.TestStepConfiguration, error) {
+	e2es := repo.Tests
+	if repo.E2E.Workflow != "" || len(e2es) == 0 {
+		e2es = append([]E2E{repo.E2E}, e2es...)
+	}
+
+	tests := []cioperatorapi.TestStepConfiguration{}
+	names := map[string]string{}
+	for _, e2e := range e2es {
+		w, ok := workflows[e2e.Workflow]
+		if !ok {
+			return tests, fmt.Errorf("unknown workflow %q, known workflows: %v", e2e.Workflow, workflowNames())
+		}
+		generated, err := w(repo, e2e)
+		if err != nil {
+			return tests, fmt.Errorf("workflow %q: %w", e2e.Workflow, err)
+		}
+		for _, test := range generated {
+			if other, ok := names[test.As]; ok {
+				return tests, fmt.Errorf("workflow %q: test %q is already generated by workflow %q, use a distinct `as`", e2e.Workflow, test.As, other)
+			}
+			names[test.As] = e2e.Workflow
+		}
+		tests = append(tests, generated...)
+	}
+	return tests, nil
+}
+
+func workflowNames() []string {
+	names := make([]string, 0, len(workflows))
+	for name := range workflows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// tasksWorkflow runs the e2e tests of task repositories against every supported OpenShift Pipelines version,
+// the test names are prefixed with the OpenShift Pipelines version.
+func tasksWorkflow(repo *Repository, e2e E2E) ([]cioperatorapi.TestStepConfiguration, error) {
+	post, err := getPostSteps(e2e.Post)
+	if err != nil {
+		return nil, err
+	}
+	tests := []cioperatorapi.TestStepConfiguration{}
+	for _, version := range repo.OpenShiftPipelines.Versions {
+		commands := fmt.Sprintf("make OSP_VERSION=%s test-e2e-openshift", version)
+		if e2e.Commands != "" {
+			commands = fmt.Sprintf("export OSP_VERSION=%s\n%s", version, e2e.Commands)
+		}
+		for _, t := range getTargets(repo, e2e) {
+			tests = append(tests, cioperatorapi.TestStepConfiguration{
+				As:                          fmt.Sprintf("osp-%s-%s", k8sNameString(version), testName(e2e, t, "ocp-%s-e2e")),
+				Cluster:                     cioperatorapi.Cluster(t.Platform.Cluster),
+				ClusterClaim:                getClusterClaim(t.OpenShift, t.Platform),
+				MultiStageTestConfiguration: claimTestConfiguration("e2e", commands, e2e.Resources, post),
+				SkipIfOnlyChanged:           skipIfOnlyChanged,
+			})
+		}
+	}
+	return tests, nil
+}
+
+// unitWorkflow runs the commands (`make test` by default) as a presubmit, without any cluster.
+func unitWorkflow(repo *Repository, e2e E2E) ([]cioperatorapi.TestStepConfiguration, error) {
+	as := e2e.As
+	if as == "" {
+		as = "unit"
+	}
+	commands := e2e.Commands
+	if commands == "" {
+		commands = "make test"
+	}
+	if len(e2e.Post) > 0 {
+		return nil, fmt.Errorf("post steps are not supported without a cluster")
+	}
+	if len(e2e.Matrix.OpenShift) > 0 || len(e2e.Matrix.Architectures) > 0 {
+		return nil, fmt.Errorf("matrix is not supported without a cluster")
+	}
+	return []cioperatorapi.TestStepConfiguration{{
+		As:      as,
+		Cluster: cioperatorapi.Cluster(getPlatform(repo, e2e).Cluster),
+		MultiStageTestConfiguration: &cioperatorapi.MultiStageTestConfiguration{
+			AllowSkipOnSuccess: pTrue(),
+			Test: []cioperatorapi.TestStep{{
+				LiteralTestStep: &cioperatorapi.LiteralTestStep{
+					As:        as,
+					Commands:  commands,
+					From:      "base-tests",
+					Resources: resourceRequirements(e2e.Resources, cioperatorapi.ResourceList{"cpu": "500m", "memory": "1Gi"}),
+				},
+			}},
+		},
+		SkipIfOnlyChanged: skipIfOnlyChanged,
+	}}, nil
+}
+
+// e2eWorkflow runs the commands (`make test-e2e` by default) as a presubmit, against a claimed cluster.
+func e2eWorkflow(repo *Repository, e2e E2E) ([]cioperatorapi.TestStepConfiguration, error) {
+	return claimWorkflow(repo, e2e, "ocp-%s-e2e")
+}
+
+// periodicWorkflow runs the commands (`make test-e2e` by default) against a claimed cluster, following the cron schedule.
+func periodicWorkflow(repo *Repository, e2e E2E) ([]cioperatorapi.TestStepConfiguration, error) {
+	if e2e.Cron == "" {
+		return nil, fmt.Errorf("cron is required for periodic tests")
+	}
+	tests, err := claimWorkflow(repo, e2e, "ocp-%s-e2e-periodic")
+	if err != nil {
+		return nil, err
+	}
+	for i := range tests {
+		tests[i].Cron = stringPtr(e2e.Cron)
+		tests[i].SkipIfOnlyChanged = ""
+	}
+	return tests, nil
+}
+
+func claimWorkflow(repo *Repository, e2e E2E, defaultName string) ([]cioperatorapi.TestStepConfiguration, error) {
+	post, err := getPostSteps(e2e.Post)
+	if err != nil {
+		return nil, err
+	}
+	commands := e2e.Commands
+	if commands == "" {
+		commands = "make test-e2e"
+	}
+	tests := []cioperatorapi.TestStepConfiguration{}
+	for _, t := range getTargets(repo, e2e) {
+		tests = append(tests, cioperatorapi.TestStepConfiguration{
+			As:                          testName(e2e, t, defaultName),
+			Cluster:                     cioperatorapi.Cluster(t.Platform.Cluster),
+			ClusterClaim:                getClusterClaim(t.OpenShift, t.Platform),
+			MultiStageTestConfiguration: claimTestConfiguration("e2e", commands, e2e.Resources, post),
+			SkipIfOnlyChanged:           skipIfOnlyChanged,
+		})
+	}
+	return tests, nil
+}
+
+// testName returns a distinct name for each entry of the test matrix.
+func testName(e2e E2E, t target, defaultName string) string {
+	if e2e.As == "" {
+		return fmt.Sprintf(defaultName, k8sNameString(t.OpenShift)) + t.Suffix
+	}
+	name := e2e.As
+	if len(e2e.Matrix.OpenShift) > 0 {
+		name += "-ocp-" + k8sNameString(t.OpenShift)
+	}
+	return name + t.Suffix
+}
+
+func claimTestConfiguration(as string, commands string, resources map[string]string, post []cioperatorapi.TestStep) *cioperatorapi.MultiStageTestConfiguration {
+	return &cioperatorapi.MultiStageTestConfiguration{
+		AllowSkipOnSuccess:       pTrue(),
+		AllowBestEffortPostSteps: pTrue(),
+		Post:                     post,
+		Test: []cioperatorapi.TestStep{{
+			LiteralTestStep: &cioperatorapi.LiteralTestStep{
+				As:        as,
+				Cli:       "latest",
+				Commands:  commands,
+				From:      "base-tests",
+				Resources: resourceRequirements(resources, cioperatorapi.ResourceList{"cpu": "100m"}),
+			},
+		}},
+		Workflow: stringPtr("generic-claim"),
+	}
+}
+
+func resourceRequirements(requests map[string]string, defaults cioperatorapi.ResourceList) cioperatorapi.ResourceRequirements {
+	if len(requests) == 0 {
+		return cioperatorapi.ResourceRequirements{Requests: defaults}
+	}
+	return cioperatorapi.ResourceRequirements{Requests: cioperatorapi.ResourceList(requests)}
+}
+
+var defaultPostSteps = []string{"openshift-pipelines-must-gather", "openshift-must-gather", "openshift-gather-extra"}
+
+// getPostSteps returns the post steps with the given names, or the default ones if none is given.
+func getPostSteps(names []string) ([]cioperatorapi.TestStep, error) {
+	if len(names) == 0 {
+		names = defaultPostSteps
+	}
+	steps := []cioperatorapi.TestStep{}
+	for _, name := range names {
+		step, ok := postSteps[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown post step %q", name)
+		}
+		steps = append(steps, cioperatorapi.TestStep{LiteralTestStep: &step})
+	}
+	return steps, nil
+}
+
+var postSteps = map[string]cioperatorapi.LiteralTestStep{
+	"openshift-pipelines-must-gather": {
+		As:                "openshift-pipelines-must-gather",
+		BestEffort:        pTrue(),
+		OptionalOnSuccess: pFalse(),
+		Cli:               "latest",
+		Commands:          "oc adm must-gather --image=quay.io/openshift-pipeline/must-gather --dest-dir \"${ARTIFACT_DIR}/gather-openshift-pipelines\"",
+		From:              "base-tests",
+		Resources: cioperatorapi.ResourceRequirements{
+			Requests: cioperatorapi.ResourceList{
+				"cpu": "100m",
+			},
+		},
+		Timeout: &prowv1.Duration{Duration: time.Duration(20) * time.Minute},
+	},
+	"openshift-must-gather": {
+		As:                "openshift-must-gather",
+		BestEffort:        pTrue(),
+		OptionalOnSuccess: pFalse(),
+		Cli:               "latest",
+		Commands:          "oc adm must-gather --dest-dir \"${ARTIFACT_DIR}/gather-openshift\"",
+		From:              "base-tests",
+		Resources: cioperatorapi.ResourceRequirements{
+			Requests: cioperatorapi.ResourceList{
+				"cpu": "100m",
+			},
+		},
+		Timeout: &prowv1.Duration{Duration: time.Duration(20) * time.Minute},
+	},
+	"openshift-gather-extra": {
+		As:                "openshift-gather-extra",
+		BestEffort:        pTrue(),
+		OptionalOnSuccess: pFalse(),
+		Cli:               "latest",
+		Commands:          "curl -skSL https://raw.githubusercontent.com/openshift/release/master/ci-operator/step-registry/gather/extra/gather-extra-commands.sh | /bin/bash -s",
+		From:              "base-tests",
+		GracePeriod:       &prowv1.Duration{Duration: time.Duration(1) * time.Minute},
+		Resources: cioperatorapi.ResourceRequirements{
+			Requests: cioperatorapi.ResourceList{
+				"cpu":    "300m",
+				"memory": "300Mi",
+			},
+		},
+		Timeout: &prowv1.Duration{Duration: time.Duration(20) * time.Minute},
+	},
+}
@@ -0,0 +1,58 @@
+package prowgen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testNames(t *testing.T, repo *Repository) []string {
+	t.Helper()
+	tests, err := generateTestFromConfig(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, test := range tests {
+		names = append(names, test.As)
+	}
+	return names
+}
+
+func TestTasksWorkflowNames(t *testing.T) {
+	repo := &Repository{
+		OpenShift:          OpenShift{Version: "4.15"},
+		OpenShiftPipelines: OpenShiftPipelines{Versions: []string{"1.14", "1.15"}},
+		E2E:                E2E{Workflow: "tasks"},
+	}
+	want := []string{"osp-114-ocp-415-e2e", "osp-115-ocp-415-e2e"}
+	if got := testNames(t, repo); !reflect.DeepEqual(got, want) {
+		t.Errorf("names = %v, want %v", got, want)
+	}
+
+	repo.E2E.As = "tasks"
+	repo.E2E.Matrix = Matrix{OpenShift: []string{"4.14", "4.15"}}
+	want = []string{"osp-114-tasks-ocp-414", "osp-114-tasks-ocp-415", "osp-115-tasks-ocp-414", "osp-115-tasks-ocp-415"}
+	if got := testNames(t, repo); !reflect.DeepEqual(got, want) {
+		t.Errorf("names = %v, want %v", got, want)
+	}
+}
+
+func TestDuplicateTestNames(t *testing.T) {
+	repo := &Repository{
+		OpenShift: OpenShift{Version: "4.15"},
+		Tests: []E2E{
+			{Workflow: "e2e", As: "e2e"},
+			{Workflow: "periodic", As: "e2e", Cron: "0 0 * * *"},
+		},
+	}
+	_, err := generateTestFromConfig(repo)
+	if err == nil || !strings.Contains(err.Error(), `test "e2e" is already generated by workflow "e2e"`) {
+		t.Fatalf("expected a duplicate test error, got %v", err)
+	}
+
+	repo.Tests[1].As = "e2e-periodic"
+	if got := testNames(t, repo); !reflect.DeepEqual(got, []string{"e2e", "e2e-periodic"}) {
+		t.Errorf("names = %v", got)
+	}
+}
@@ -0,0 +1,54 @@
+package release
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/openshift-pipelines-konflux/hack/internal/konflux"
+)
+
+// RegistryChecker finds the released patch versions from the tags of the images in the release registry.
+type RegistryChecker struct {
+	// Registry is the registry the images are released to.
+	Registry string
+	Options  []crane.Option
+}
+
+// Released reports whether the released images of every component of the application are tagged with
+// the patch version. The file-based catalogs are not copied to the release registry and are not checked.
+func (r RegistryChecker) Released(ctx context.Context, application konflux.Application, patchVersion konflux.ReleaseVersion) (bool, error) {
+	options := append([]crane.Option{crane.WithContext(ctx)}, r.Options...)
+	for _, c := range application.Components {
+		if c.Build.Kind == konflux.BuildKindFBC {
+			continue
+		}
+		image := c.Images(r.Registry).Release
+		tags, err := crane.ListTags(image, options...)
+		var terr *transport.Error
+		if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+			log.Printf("%s was never released", image)
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if !contains(tags, patchVersion.String()) {
+			log.Printf("%s has no %s tag", image, patchVersion)
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package release
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/openshift-pipelines-konflux/hack/internal/konflux"
+)
+
+func TestRegistryChecker(t *testing.T) {
+	ctx := context.Background()
+	host := testRegistry(t)
+
+	application := konflux.Application{Name: "pipelines-index-4.15", Tenant: konflux.Tenant{Registry: "quay.io/tenant"}}
+	application.Components = []konflux.Component{
+		{Name: "pipeline-cli", Application: &application, Build: konflux.Build{Kind: konflux.BuildKindContainer}},
+		// The file-based catalog is never copied to the release registry
+		{Name: "pipeline-fbc", Application: &application, Build: konflux.Build{Kind: konflux.BuildKindFBC}},
+	}
+	patchVersion, err := konflux.ParseReleaseVersion("1.15.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checker := RegistryChecker{Registry: host + "/release"}
+
+	// The image was never released
+	released, err := checker.Released(ctx, application, patchVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if released {
+		t.Error("expected 1.15.1 not to be released without image")
+	}
+
+	image, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.Push(image, host+"/release/cli:1.15.0"); err != nil {
+		t.Fatal(err)
+	}
+	released, err = checker.Released(ctx, application, patchVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if released {
+		t.Error("expected 1.15.1 not to be released without tag")
+	}
+
+	// Released once the container image is tagged, the FBC component is skipped
+	if err := crane.Tag(host+"/release/cli:1.15.0", "1.15.1"); err != nil {
+		t.Fatal(err)
+	}
+	released, err = checker.Released(ctx, application, patchVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !released {
+		t.Error("expected 1.15.1 to be released")
+	}
+}
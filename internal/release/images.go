@@ -0,0 +1,45 @@
+package release
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/openshift-pipelines-konflux/hack/internal/konflux"
+)
+
+// TargetImage returns the reference of the released image, keeping the digest of the image built in
+// the tenant registry.
+func TargetImage(containerImage string, targetRegistry string) (name.Digest, error) {
+	src, err := name.NewDigest(containerImage)
+	if err != nil {
+		return name.Digest{}, fmt.Errorf("image %s must be referenced by digest: %w", containerImage, err)
+	}
+	return name.NewDigest(konflux.ReleaseRepository(src.Context().Name(), targetRegistry) + "@" + src.DigestStr())
+}
+
+// Tags returns the tags of a released image.
+func Tags(version string, image name.Digest) []string {
+	return konflux.ReleaseTags(version, image.DigestStr())
+}
+
+// CopyImage copies the image (or image index, with all its platforms) to every tag of the target
+// repository, preserving the digests.
+func CopyImage(ctx context.Context, src string, target name.Digest, tags []string, options ...crane.Option) error {
+	options = append([]crane.Option{crane.WithContext(ctx)}, options...)
+	for _, tag := range tags {
+		dst := target.Context().Tag(tag).String()
+		if err := crane.Copy(src, dst, options...); err != nil {
+			return fmt.Errorf("failed to copy %s to %s: %w", src, dst, err)
+		}
+		digest, err := crane.Digest(dst, options...)
+		if err != nil {
+			return err
+		}
+		if digest != target.DigestStr() {
+			return fmt.Errorf("digest of %s is %s, expected %s", dst, digest, target.DigestStr())
+		}
+	}
+	return nil
+}
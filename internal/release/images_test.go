@@ -0,0 +1,124 @@
+package release
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// testRegistry starts an in-memory registry and returns its host.
+func testRegistry(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(registry.New())
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Host
+}
+
+func TestCopyImage(t *testing.T) {
+	ctx := context.Background()
+	host := testRegistry(t)
+
+	index, err := random.Index(64, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := name.ParseReference(host + "/tenant/pipeline-cli:build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(src, index); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := index.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	containerImage := host + "/tenant/pipeline-cli@" + digest.String()
+
+	target, err := TargetImage(containerImage, host+"/release")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := target.String(), host+"/release/cli@"+digest.String(); got != want {
+		t.Fatalf("target = %s, want %s", got, want)
+	}
+	tags := Tags("1.15.1", target)
+	if want := []string{"1.15.1", "sha-" + digest.Hex}; strings.Join(tags, ",") != strings.Join(want, ",") {
+		t.Fatalf("tags = %v, want %v", tags, want)
+	}
+	if err := CopyImage(ctx, containerImage, target, tags); err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range tags {
+		got, err := crane.Digest(host + "/release/cli:" + tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != digest.String() {
+			t.Errorf("tag %s has digest %s, want %s", tag, got, digest)
+		}
+	}
+	// The platforms of the index are copied along
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range manifest.Manifests {
+		if _, err := crane.Manifest(host + "/release/cli@" + m.Digest.String()); err != nil {
+			t.Errorf("image %s of the index is not copied: %v", m.Digest, err)
+		}
+	}
+}
+
+func TestCopyImageDigestMismatch(t *testing.T) {
+	ctx := context.Background()
+	host := testRegistry(t)
+
+	image, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.Push(image, host+"/tenant/pipeline-cli:build"); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := image.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherDigest, err := other.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The target is expected to keep the digest of another image
+	target, err := name.NewDigest(host + "/release/cli@" + otherDigest.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = CopyImage(ctx, host+"/tenant/pipeline-cli@"+digest.String(), target, []string{"1.15.1"})
+	if err == nil || !strings.Contains(err.Error(), "expected "+otherDigest.String()) {
+		t.Errorf("expected a digest mismatch error, got %v", err)
+	}
+}
+
+func TestTargetImageRequiresDigest(t *testing.T) {
+	if _, err := TargetImage("quay.io/tenant/pipeline-cli:latest", "quay.io/openshift-pipeline"); err == nil {
+		t.Error("expected an error for an image referenced by tag")
+	}
+}
@@ -0,0 +1,60 @@
+package release
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// componentLabel is the label of the Snapshots created for a single component.
+const componentLabel = "appstudio.openshift.io/component"
+
+// Snapshot is the subset of the appstudio.redhat.com/v1alpha1 Snapshot used by the release.
+type Snapshot struct {
+	Metadata struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Spec struct {
+		Application string              `json:"application"`
+		Components  []SnapshotComponent `json:"components"`
+	} `json:"spec"`
+}
+
+type SnapshotComponent struct {
+	Name           string `json:"name"`
+	ContainerImage string `json:"containerImage"`
+	Source         struct {
+		Git struct {
+			URL      string `json:"url"`
+			Revision string `json:"revision"`
+		} `json:"git"`
+	} `json:"source"`
+}
+
+// ReadSnapshot reads a Snapshot from a JSON file.
+func ReadSnapshot(path string) (*Snapshot, error) {
+	in, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{}
+	if err := json.Unmarshal(in, s); err != nil {
+		return nil, fmt.Errorf("error while parsing snapshot %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// ReleasedComponent returns the component the Snapshot was created for.
+func (s *Snapshot) ReleasedComponent() (*SnapshotComponent, error) {
+	name := s.Metadata.Labels[componentLabel]
+	if name == "" {
+		return nil, fmt.Errorf("snapshot %s has no %s label", s.Metadata.Name, componentLabel)
+	}
+	for i := range s.Spec.Components {
+		if s.Spec.Components[i].Name == name {
+			return &s.Spec.Components[i], nil
+		}
+	}
+	return nil, fmt.Errorf("component %s not found in snapshot %s", name, s.Metadata.Name)
+}